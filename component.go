@@ -44,6 +44,8 @@ const (
 	ComponentTZMinute
 	ComponentISOYear
 	ComponentISODOW
+	ComponentAMPM
+	ComponentADBC
 
 	ComponentTimeMask = (ComponentHour | ComponentMinute | ComponentSecond)
 	ComponentDateMask = (ComponentDay | ComponentMonth | ComponentYear)
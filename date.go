@@ -0,0 +1,103 @@
+package pgdatetime
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date, as stored by the PostgreSQL date type.
+type Date struct {
+	// Year is the year, where as with time.Time, 1 BC is year 0, 2 BC is
+	// year -1 and so on.
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateFromTime returns the date of the given time in its location.
+func DateFromTime(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Time returns midnight at the start of the date in the given location.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// ParseDateResult returns the result of parsing a date.
+// Date is not set if Type is ParseResultTypePosInfinity or
+// ParseResultTypeNegInfinity.
+type ParseDateResult struct {
+	Type ParseResultType
+	Date Date
+}
+
+// ParseDate parses a Date element. As in PostgreSQL, a time or time zone in
+// the input is validated but otherwise discarded.
+func ParseDate(
+	dateStyle DateStyle, now time.Time, s string, opts ...ParseOption,
+) (ParseDateResult, error) {
+	tokens, err := tokenizeDateTime(s)
+	if err != nil {
+		return ParseDateResult{}, err
+	}
+	r, err := decodeTokens(valueTypeDate, dateStyle, now, tokens, makeParseOptions(opts))
+	if err != nil {
+		return ParseDateResult{}, err
+	}
+	ret := ParseDateResult{Type: r.Type}
+	if r.Type == ParseResultTypeAbsoluteTime || r.Type == ParseResultTypeRelativeTime {
+		ret.Date = DateFromTime(r.Time)
+	}
+	return ret, nil
+}
+
+// WriteDateToBuffer writes the given date into the given buffer.
+// This is a port of PostgreSQL's EncodeDateOnly.
+func WriteDateToBuffer(buf *bytes.Buffer, ds DateStyle, r ParseDateResult) {
+	switch r.Type {
+	case ParseResultTypePosInfinity:
+		buf.WriteString("infinity")
+		return
+	case ParseResultTypeNegInfinity:
+		buf.WriteString("-infinity")
+		return
+	}
+	d := r.Date
+	year := d.Year
+	if year <= 0 {
+		year = -year + 1
+	}
+	switch ds.Style {
+	case StyleSQL:
+		if ds.Order == OrderDMY {
+			fmt.Fprintf(buf, "%02d/%02d/%04d", d.Day, d.Month, year)
+		} else {
+			fmt.Fprintf(buf, "%02d/%02d/%04d", d.Month, d.Day, year)
+		}
+	case StyleGerman:
+		fmt.Fprintf(buf, "%02d.%02d.%04d", d.Day, d.Month, year)
+	case StylePostgres:
+		if ds.Order == OrderDMY {
+			fmt.Fprintf(buf, "%02d-%02d-%04d", d.Day, d.Month, year)
+		} else {
+			fmt.Fprintf(buf, "%02d-%02d-%04d", d.Month, d.Day, year)
+		}
+	default:
+		fmt.Fprintf(buf, "%04d-%02d-%02d", year, d.Month, d.Day)
+	}
+	if d.Year <= 0 {
+		buf.WriteString(" BC")
+	}
+}
+
+// FormatDate formats the given date as the given DateStyle. As in
+// PostgreSQL, dates in SQL and Postgres styles are written in MDY order
+// unless the order is DMY.
+func FormatDate(ds DateStyle, r ParseDateResult) string {
+	var b bytes.Buffer
+	WriteDateToBuffer(&b, ds, r)
+	return b.String()
+}
@@ -0,0 +1,1010 @@
+package pgdatetime
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	monthsPerYear   = 12
+	daysPerMonth    = 30
+	daysPerWeek     = 7
+	microsPerSecond = int64(1000000)
+	microsPerMinute = 60 * microsPerSecond
+	microsPerHour   = 60 * microsPerMinute
+	microsPerDay    = 24 * microsPerHour
+)
+
+// Interval is a span of time, as stored by the PostgreSQL interval type.
+// As the number of days in a month and the length of a day vary, months,
+// days and microseconds are stored separately.
+type Interval struct {
+	Months int32
+	Days   int32
+	Micros int64
+}
+
+// IntervalQualifier refers to the fields of an interval allowed by a SQL
+// interval type, e.g. INTERVAL YEAR TO MONTH.
+// See also: https://www.postgresql.org/docs/current/datatype-datetime.html#DATATYPE-INTERVAL-INPUT
+type IntervalQualifier uint8
+
+//go:generate stringer -type=IntervalQualifier -trimprefix=IntervalQualifier
+
+const (
+	// IntervalQualifierFull allows all fields, i.e. an unqualified interval.
+	IntervalQualifierFull IntervalQualifier = iota
+	IntervalQualifierYear
+	IntervalQualifierMonth
+	IntervalQualifierDay
+	IntervalQualifierHour
+	IntervalQualifierMinute
+	IntervalQualifierSecond
+	IntervalQualifierYearToMonth
+	IntervalQualifierDayToHour
+	IntervalQualifierDayToMinute
+	IntervalQualifierDayToSecond
+	IntervalQualifierHourToMinute
+	IntervalQualifierHourToSecond
+	IntervalQualifierMinuteToSecond
+)
+
+// maxIntervalPrecision is the maximum number of fractional digits of
+// seconds in an interval.
+const maxIntervalPrecision = 6
+
+// defaultUnit returns the unit of a number without a unit at the end of an
+// interval, i.e. the last field allowed by the qualifier.
+func (q IntervalQualifier) defaultUnit() Component {
+	switch q {
+	case IntervalQualifierYear:
+		return ComponentYear
+	case IntervalQualifierMonth, IntervalQualifierYearToMonth:
+		return ComponentMonth
+	case IntervalQualifierDay:
+		return ComponentDay
+	case IntervalQualifierHour, IntervalQualifierDayToHour:
+		return ComponentHour
+	case IntervalQualifierMinute, IntervalQualifierHourToMinute, IntervalQualifierDayToMinute:
+		return ComponentMinute
+	default:
+		return ComponentSecond
+	}
+}
+
+// ParseInterval parses an Interval element, e.g. "1 day 12:00:00",
+// "@ 1 year 2 mons ago" or the ISO 8601 duration "P1Y2M3DT4H5M6S".
+func ParseInterval(s string, opts ...ParseOption) (Interval, error) {
+	o := makeParseOptions(opts)
+	var iv Interval
+	if strings.HasPrefix(s, "P") {
+		var err error
+		if iv, err = decodeISO8601Interval(s); err != nil {
+			return Interval{}, err
+		}
+	} else {
+		tokens, err := tokenizeDateTime(s)
+		if err != nil {
+			return Interval{}, err
+		}
+		if iv, err = decodeInterval(tokens, o); err != nil {
+			return Interval{}, err
+		}
+	}
+	return adjustIntervalForQualifier(iv, o.intervalQualifier, o.intervalPrecision)
+}
+
+// adjustIntervalForQualifier truncates the fields of the interval which are
+// not allowed by the qualifier, and rounds the interval to the given
+// number of fractional digits of seconds. A negative precision leaves the
+// fractional seconds unchanged.
+// This is a port of PostgreSQL's AdjustIntervalForTypmod.
+func adjustIntervalForQualifier(iv Interval, q IntervalQualifier, precision int) (Interval, error) {
+	switch q {
+	case IntervalQualifierYear:
+		iv.Months = (iv.Months / monthsPerYear) * monthsPerYear
+		iv.Days = 0
+		iv.Micros = 0
+	case IntervalQualifierMonth, IntervalQualifierYearToMonth:
+		iv.Days = 0
+		iv.Micros = 0
+	case IntervalQualifierDay:
+		iv.Micros = 0
+	case IntervalQualifierHour, IntervalQualifierDayToHour:
+		iv.Micros = (iv.Micros / microsPerHour) * microsPerHour
+	case IntervalQualifierMinute, IntervalQualifierDayToMinute, IntervalQualifierHourToMinute:
+		iv.Micros = (iv.Micros / microsPerMinute) * microsPerMinute
+	}
+	if precision >= 0 && precision < maxIntervalPrecision {
+		scale := int64(1)
+		for i := precision; i < maxIntervalPrecision; i++ {
+			scale *= 10
+		}
+		// Round half away from zero.
+		micros := iv.Micros
+		if micros < 0 {
+			micros = -micros
+		}
+		micros, ok := addInt64(micros, scale/2)
+		if !ok {
+			return Interval{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "interval out of range")
+		}
+		micros = (micros / scale) * scale
+		if iv.Micros < 0 {
+			micros = -micros
+		}
+		iv.Micros = micros
+	}
+	return iv, nil
+}
+
+// decodeIntervalState is the state of decoding an interval. Years are kept
+// separately from months until the end so overflow can be detected.
+type decodeIntervalState struct {
+	seen                        Component
+	years, months, days, micros int64
+}
+
+// decodeInterval decodes an interval from the given tokens.
+// This is a port of PostgreSQL's DecodeInterval.
+func decodeInterval(tokens []token, opts parseOptions) (Interval, error) {
+	var s decodeIntervalState
+	// unit is the unit of the next number. As we read the tokens backwards,
+	// units are read before the numbers they apply to.
+	var unit Component
+	// unitIdx is the index of the unit token, if unit has not yet been
+	// applied to a number.
+	unitIdx := -1
+	isBefore := false
+	// In the SQL standard style, a leading minus sign applies to all
+	// fields, unless other fields have explicit signs.
+	forceNegative := false
+	if opts.intervalStyle == IntervalStyleSQLStandard && len(tokens) > 0 && tokens[0].val[0] == '-' {
+		forceNegative = true
+		for _, t := range tokens[1:] {
+			if t.val[0] == '-' || t.val[0] == '+' {
+				forceNegative = false
+				break
+			}
+		}
+	}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		t := tokens[i]
+		var mask Component
+		switch t.tokenType {
+		case tokenTypeTime:
+			micros, err := decodeIntervalTime(t, opts.intervalQualifier)
+			if err != nil {
+				return Interval{}, err
+			}
+			if forceNegative {
+				micros = -micros
+			}
+			if err := s.addMicros(t, micros); err != nil {
+				return Interval{}, err
+			}
+			mask = ComponentTimeMask
+			// A number before a time is a number of days, e.g. 1 12:00:00.
+			unit = ComponentDay
+			unitIdx = -1
+		case tokenTypeTZ, tokenTypeDate, tokenTypeNumber:
+			// A signed time, e.g. -12:00:00.
+			if val := removeSpaces(t.val); t.tokenType == tokenTypeTZ && strings.IndexByte(val, ':') != -1 {
+				if micros, err := decodeIntervalTime(token{val: val[1:], raw: t.raw, idx: t.idx}, opts.intervalQualifier); err == nil {
+					if val[0] == '-' || forceNegative {
+						micros = -micros
+					}
+					if err := s.addMicros(t, micros); err != nil {
+						return Interval{}, err
+					}
+					mask = ComponentTimeMask
+					unit = ComponentDay
+					unitIdx = -1
+					break
+				}
+			}
+			if unit == 0 {
+				unit = opts.intervalQualifier.defaultUnit()
+			}
+			var err error
+			if mask, unit, err = s.decodeNumber(t, unit, forceNegative); err != nil {
+				return Interval{}, err
+			}
+			unitIdx = -1
+		case tokenTypeString, tokenTypeSpecial:
+			if unitIdx != -1 {
+				return Interval{}, NewParseErrorf(tokens[unitIdx].idx, "expected value before %s", tokens[unitIdx].raw)
+			}
+			kw, ok := intervalUnits[removeSpaces(t.val)]
+			if !ok {
+				return Interval{}, NewParseErrorf(t.idx, "unknown unit: %s", t.raw)
+			}
+			if kw.typ == ComponentAgo {
+				if i != len(tokens)-1 {
+					return Interval{}, NewParseErrorf(t.idx, "unexpected %s before end of interval", t.raw)
+				}
+				isBefore = true
+				// A unit must precede ago.
+				unit = ComponentAgo
+				continue
+			}
+			unit = kw.typ
+			unitIdx = i
+		default:
+			return Interval{}, NewParseErrorf(t.idx, "unexpected token in interval: %s", t.raw)
+		}
+		if s.seen&mask != 0 {
+			return Interval{}, NewParseErrorf(t.idx, "duplicate field in interval: %s", t.raw)
+		}
+		s.seen |= mask
+	}
+	if unitIdx != -1 {
+		return Interval{}, NewParseErrorf(tokens[unitIdx].idx, "expected value before %s", tokens[unitIdx].raw)
+	}
+	if s.seen == 0 {
+		return Interval{}, NewParseError(0, "missing interval")
+	}
+	if isBefore {
+		if s.micros == math.MinInt64 {
+			return Interval{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "interval out of range")
+		}
+		s.years, s.months, s.days, s.micros = -s.years, -s.months, -s.days, -s.micros
+	}
+	return s.toInterval()
+}
+
+// decodeISO8601Interval decodes an ISO 8601 duration, either in the format
+// with designators, e.g. P1Y2M3DT4H5M6.5S or P2W, or in the alternative
+// format, e.g. P0001-02-03T04:05:06 or P00010203T040506.
+// This is a port of PostgreSQL's DecodeISO8601Interval.
+func decodeISO8601Interval(str string) (Interval, error) {
+	var s decodeIntervalState
+	if len(str) < 2 || str[0] != 'P' {
+		return Interval{}, NewParseErrorf(0, "invalid ISO 8601 duration: %s", str)
+	}
+	badFormat := func(i int) error {
+		return NewParseErrorf(i, "invalid ISO 8601 duration: %s", str)
+	}
+	i := 1
+	isDatePart := true
+	haveField := false
+	// nextNumber reads the next number, returning a token for error
+	// reporting.
+	nextNumber := func() (token, int64, float64, error) {
+		start := i
+		val, frac, n, err := parseISO8601Number(str[i:])
+		if err != nil {
+			err.Idx += start
+			return token{}, 0, 0, err
+		}
+		i += n
+		return token{val: str[start:i], raw: str[start:i], idx: start}, val, frac, nil
+	}
+	for i < len(str) {
+		if str[i] == 'T' {
+			// T indicates the beginning of the time part.
+			isDatePart = false
+			haveField = false
+			i++
+			continue
+		}
+		t, val, frac, err := nextNumber()
+		if err != nil {
+			return Interval{}, err
+		}
+		var unit byte
+		if i < len(str) {
+			unit = str[i]
+			i++
+		}
+		if isDatePart {
+			switch unit {
+			case 'Y':
+				err = s.adjustYears(t, val, frac, 1)
+			case 'M':
+				if err = s.adjustMonths(t, val); err == nil {
+					err = s.adjustFractDays(t, frac, daysPerMonth)
+				}
+			case 'W':
+				if err = s.adjustDays(t, val, daysPerWeek); err == nil {
+					err = s.adjustFractDays(t, frac, daysPerWeek)
+				}
+			case 'D':
+				if err = s.adjustDays(t, val, 1); err == nil {
+					err = s.adjustFractMicros(t, frac, microsPerDay)
+				}
+			case 'T', 0, '-':
+				if haveField {
+					return Interval{}, badFormat(t.idx)
+				}
+				if (unit == 'T' || unit == 0) && iso8601IntegerWidth(t.val) == 8 {
+					// The basic alternative format, e.g. 00010203.
+					if err := s.adjustYears(t, val/10000, 0, 1); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustMonths(t, (val/100)%100); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustDays(t, val%100, 1); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustFractMicros(t, frac, microsPerDay); err != nil {
+						return Interval{}, err
+					}
+					isDatePart = false
+					continue
+				}
+				// The extended alternative format, e.g. 0001-02-03.
+				if err := s.adjustYears(t, val, frac, 1); err != nil {
+					return Interval{}, err
+				}
+				if unit == 'T' || unit == 0 {
+					isDatePart = false
+					continue
+				}
+				if t, val, frac, err = nextNumber(); err != nil {
+					return Interval{}, err
+				}
+				if err := s.adjustMonths(t, val); err != nil {
+					return Interval{}, err
+				}
+				if err := s.adjustFractDays(t, frac, daysPerMonth); err != nil {
+					return Interval{}, err
+				}
+				if i < len(str) && str[i] == '-' {
+					i++
+					if t, val, frac, err = nextNumber(); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustDays(t, val, 1); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustFractMicros(t, frac, microsPerDay); err != nil {
+						return Interval{}, err
+					}
+				}
+				if i < len(str) && str[i] != 'T' {
+					return Interval{}, badFormat(i)
+				}
+				continue
+			default:
+				return Interval{}, badFormat(i - 1)
+			}
+		} else {
+			switch unit {
+			case 'H':
+				err = s.adjustMicros(t, val, frac, microsPerHour)
+			case 'M':
+				err = s.adjustMicros(t, val, frac, microsPerMinute)
+			case 'S':
+				err = s.adjustMicros(t, val, frac, microsPerSecond)
+			case 0, ':':
+				if haveField {
+					return Interval{}, badFormat(t.idx)
+				}
+				if unit == 0 && iso8601IntegerWidth(t.val) == 6 {
+					// The basic alternative format, e.g. 040506.
+					if err := s.adjustMicros(t, val/10000, 0, microsPerHour); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustMicros(t, (val/100)%100, 0, microsPerMinute); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustMicros(t, val%100, 0, microsPerSecond); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustFractMicros(t, frac, microsPerSecond); err != nil {
+						return Interval{}, err
+					}
+					return s.toInterval()
+				}
+				// The extended alternative format, e.g. 04:05:06.
+				if err := s.adjustMicros(t, val, frac, microsPerHour); err != nil {
+					return Interval{}, err
+				}
+				if unit == 0 {
+					return s.toInterval()
+				}
+				if t, val, frac, err = nextNumber(); err != nil {
+					return Interval{}, err
+				}
+				if err := s.adjustMicros(t, val, frac, microsPerMinute); err != nil {
+					return Interval{}, err
+				}
+				if i < len(str) && str[i] == ':' {
+					i++
+					if t, val, frac, err = nextNumber(); err != nil {
+						return Interval{}, err
+					}
+					if err := s.adjustMicros(t, val, frac, microsPerSecond); err != nil {
+						return Interval{}, err
+					}
+				}
+				if i < len(str) {
+					return Interval{}, badFormat(i)
+				}
+				return s.toInterval()
+			default:
+				return Interval{}, badFormat(i - 1)
+			}
+		}
+		if err != nil {
+			return Interval{}, err
+		}
+		haveField = true
+	}
+	return s.toInterval()
+}
+
+// parseISO8601Number parses the decimal number at the start of the given
+// string, returning its integer and fractional parts and the number of
+// bytes read.
+func parseISO8601Number(str string) (val int64, frac float64, n int, err *ParseError) {
+	if n < len(str) && str[n] == '-' {
+		n++
+	}
+	digitsStart := n
+	for n < len(str) && str[n] >= '0' && str[n] <= '9' {
+		n++
+	}
+	if n < len(str) && str[n] == '.' {
+		n++
+		for n < len(str) && str[n] >= '0' && str[n] <= '9' {
+			n++
+		}
+	}
+	if n == digitsStart || str[digitsStart:n] == "." {
+		return 0, 0, 0, NewParseErrorf(0, "expected number in ISO 8601 duration, found %q", str)
+	}
+	f, parseErr := strconv.ParseFloat(str[:n], 64)
+	if parseErr != nil || f < -1e15 || f > 1e15 {
+		return 0, 0, 0, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: %s", str[:n])
+	}
+	// Truncate towards zero, so the fractional part has the same sign.
+	val = int64(f)
+	return val, f - float64(val), n, nil
+}
+
+// iso8601IntegerWidth returns the number of digits in the integer part of
+// the given number, ignoring any sign.
+func iso8601IntegerWidth(s string) int {
+	s = strings.TrimPrefix(s, "-")
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// toInterval returns the decoded interval, checking it is in range.
+func (s *decodeIntervalState) toInterval() (Interval, error) {
+	months := s.years*monthsPerYear + s.months
+	if months > math.MaxInt32 || months < math.MinInt32 ||
+		s.days > math.MaxInt32 || s.days < math.MinInt32 {
+		return Interval{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "interval out of range")
+	}
+	return Interval{Months: int32(months), Days: int32(s.days), Micros: s.micros}, nil
+}
+
+// decodeNumber decodes a number in an interval with the given unit, e.g.
+// 1, -1.5 or 1-2 (one year and two months). It returns the Components seen
+// and the unit of the next number. If forceNegative is set, the number is
+// made negative.
+func (s *decodeIntervalState) decodeNumber(
+	t token, unit Component, forceNegative bool,
+) (Component, Component, error) {
+	val, frac, isYearMonth, err := parseIntervalNumber(t)
+	if err != nil {
+		return 0, 0, err
+	}
+	if forceNegative {
+		if val > 0 {
+			val = -val
+		}
+		if frac > 0 {
+			frac = -frac
+		}
+	}
+	if isYearMonth {
+		unit = ComponentMonth
+	}
+	var mask Component
+	switch unit {
+	case ComponentMicros:
+		err = s.adjustMicros(t, val, frac, 1)
+		mask = ComponentMicros
+	case ComponentMillis:
+		err = s.adjustMicros(t, val, frac, 1000)
+		mask = ComponentMillis
+	case ComponentSecond:
+		err = s.adjustMicros(t, val, frac, microsPerSecond)
+		// Fractional seconds also count as milliseconds and microseconds.
+		mask = ComponentSecond
+		if frac != 0 {
+			mask |= ComponentMillis | ComponentMicros
+		}
+	case ComponentMinute:
+		err = s.adjustMicros(t, val, frac, microsPerMinute)
+		mask = ComponentMinute
+	case ComponentHour:
+		err = s.adjustMicros(t, val, frac, microsPerHour)
+		mask = ComponentHour
+		// A number before a number of hours is a number of days.
+		unit = ComponentDay
+	case ComponentDay:
+		if err = s.adjustDays(t, val, 1); err == nil {
+			err = s.adjustFractMicros(t, frac, microsPerDay)
+		}
+		mask = ComponentDay
+	case ComponentWeek:
+		if err = s.adjustDays(t, val, daysPerWeek); err == nil {
+			err = s.adjustFractDays(t, frac, daysPerWeek)
+		}
+		mask = ComponentWeek
+	case ComponentMonth:
+		if err = s.adjustMonths(t, val); err == nil {
+			err = s.adjustFractDays(t, frac, daysPerMonth)
+		}
+		mask = ComponentMonth
+	case ComponentYear:
+		err = s.adjustYears(t, val, frac, 1)
+		mask = ComponentYear
+	case ComponentDecade:
+		err = s.adjustYears(t, val, frac, 10)
+		mask = ComponentDecade
+	case ComponentCentury:
+		err = s.adjustYears(t, val, frac, 100)
+		mask = ComponentCentury
+	case ComponentMillennium:
+		err = s.adjustYears(t, val, frac, 1000)
+		mask = ComponentMillennium
+	default:
+		return 0, 0, NewParseErrorf(t.idx, "expected unit after %s", t.raw)
+	}
+	return mask, unit, err
+}
+
+// parseIntervalNumber parses a possibly signed number in an interval. The
+// number may have a fractional part, or be a number of years and months
+// separated by '-', in which case val is the total number of months.
+func parseIntervalNumber(t token) (val int64, frac float64, isYearMonth bool, err error) {
+	str := removeSpaces(t.val)
+	i := 0
+	if str[0] == '+' || str[0] == '-' {
+		i++
+	}
+	start := i
+	for i < len(str) && str[i] >= '0' && str[i] <= '9' {
+		i++
+	}
+	if i > start {
+		if val, err = strconv.ParseInt(str[:i], 10, 64); err != nil {
+			return 0, 0, false, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+		}
+	}
+	rest := str[i:]
+	switch {
+	case rest == "":
+		if i == start {
+			return 0, 0, false, NewParseErrorf(t.idx, "invalid number in interval: %s", t.raw)
+		}
+	case rest[0] == '-' && i > start:
+		// SQL standard years-months, e.g. 1-2.
+		monthsStr := rest[1:]
+		if monthsStr == "" || strings.Trim(monthsStr, "0123456789") != "" {
+			return 0, 0, false, NewParseErrorf(t.idx, "invalid number in interval: %s", t.raw)
+		}
+		months, err := strconv.ParseInt(monthsStr, 10, 64)
+		if err != nil || months >= monthsPerYear {
+			return 0, 0, false, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+		}
+		if str[0] == '-' {
+			months = -months
+		}
+		if val > math.MaxInt64/monthsPerYear || val < math.MinInt64/monthsPerYear {
+			return 0, 0, false, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+		}
+		return val*monthsPerYear + months, 0, true, nil
+	case rest[0] == '.':
+		if strings.Trim(rest[1:], "0123456789") != "" {
+			return 0, 0, false, NewParseErrorf(t.idx, "invalid number in interval: %s", t.raw)
+		}
+		if len(rest) > 1 {
+			if frac, err = strconv.ParseFloat(rest, 64); err != nil {
+				return 0, 0, false, NewParseErrorf(t.idx, "invalid number in interval: %s", t.raw)
+			}
+		}
+		if str[0] == '-' {
+			frac = -frac
+		}
+	default:
+		return 0, 0, false, NewParseErrorf(t.idx, "invalid number in interval: %s", t.raw)
+	}
+	return val, frac, false, nil
+}
+
+// decodeIntervalTime decodes a time in an interval, e.g. 12:00, 12:00:00.5
+// or 1:02.5 (one minute and 2.5 seconds), returning the time in
+// microseconds. Unlike a time of day, the hours are unbounded. If the
+// qualifier is MINUTE TO SECOND, 1:02 is read as minutes and seconds.
+func decodeIntervalTime(t token, q IntervalQualifier) (int64, error) {
+	fields := strings.Split(t.val, ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, NewParseErrorf(t.idx, "invalid time in interval: %s", t.raw)
+	}
+	var hour, minute, second, frac float64
+	var err error
+	parseField := func(s string) (float64, error) {
+		if s == "" || strings.Trim(s, "0123456789") != "" {
+			return 0, NewParseErrorf(t.idx, "invalid time in interval: %s", t.raw)
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+		}
+		return float64(v), nil
+	}
+	// The last field may have a fractional part.
+	last := fields[len(fields)-1]
+	if dotIdx := strings.IndexByte(last, '.'); dotIdx != -1 {
+		if strings.Trim(last[dotIdx+1:], "0123456789") != "" {
+			return 0, NewParseErrorf(t.idx, "invalid time in interval: %s", t.raw)
+		}
+		if dotIdx+1 < len(last) {
+			if frac, err = strconv.ParseFloat(last[dotIdx:], 64); err != nil {
+				return 0, NewParseErrorf(t.idx, "invalid time in interval: %s", t.raw)
+			}
+		}
+		fields[len(fields)-1] = last[:dotIdx]
+	}
+	if hour, err = parseField(fields[0]); err != nil {
+		return 0, err
+	}
+	if minute, err = parseField(fields[1]); err != nil {
+		return 0, err
+	}
+	switch {
+	case len(fields) == 3:
+		if second, err = parseField(fields[2]); err != nil {
+			return 0, err
+		}
+	case strings.IndexByte(last, '.') != -1 || q == IntervalQualifierMinuteToSecond:
+		// mm:ss.fff is always minutes and seconds.
+		hour, minute, second = 0, hour, minute
+	}
+	fracMicros := int64(math.RoundToEven(frac * float64(microsPerSecond)))
+	if minute > 59 || second > 60 || fracMicros > microsPerSecond {
+		return 0, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+	}
+	return int64(hour)*microsPerHour + int64(minute)*microsPerMinute + int64(second)*microsPerSecond + fracMicros, nil
+}
+
+func (s *decodeIntervalState) outOfRange(t token) error {
+	return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "interval field value out of range: %s", t.raw)
+}
+
+// addMicros adds the given number of microseconds to the interval.
+func (s *decodeIntervalState) addMicros(t token, micros int64) error {
+	sum, ok := addInt64(s.micros, micros)
+	if !ok {
+		return s.outOfRange(t)
+	}
+	s.micros = sum
+	return nil
+}
+
+// adjustMicros adds val+frac units of the given number of microseconds to
+// the interval.
+func (s *decodeIntervalState) adjustMicros(t token, val int64, frac float64, scale int64) error {
+	micros, ok := mulInt64(val, scale)
+	if !ok {
+		return s.outOfRange(t)
+	}
+	if err := s.addMicros(t, micros); err != nil {
+		return err
+	}
+	return s.adjustFractMicros(t, frac, scale)
+}
+
+// adjustFractMicros adds frac units of the given number of microseconds to
+// the interval, rounded to the nearest microsecond.
+func (s *decodeIntervalState) adjustFractMicros(t token, frac float64, scale int64) error {
+	if frac == 0 {
+		return nil
+	}
+	frac *= float64(scale)
+	if frac >= math.MaxInt64 || frac < math.MinInt64 {
+		return s.outOfRange(t)
+	}
+	micros := int64(frac)
+	micros += int64(math.RoundToEven(frac - float64(micros)))
+	return s.addMicros(t, micros)
+}
+
+// adjustDays adds val units of the given number of days to the interval.
+func (s *decodeIntervalState) adjustDays(t token, val int64, scale int64) error {
+	days, ok := mulInt64(val, scale)
+	if !ok {
+		return s.outOfRange(t)
+	}
+	if s.days, ok = addInt64(s.days, days); !ok || s.days > math.MaxInt32 || s.days < math.MinInt32 {
+		return s.outOfRange(t)
+	}
+	return nil
+}
+
+// adjustFractDays adds frac units of the given number of days to the
+// interval, with any fractional day cascading down to microseconds.
+func (s *decodeIntervalState) adjustFractDays(t token, frac float64, scale int64) error {
+	if frac == 0 {
+		return nil
+	}
+	frac *= float64(scale)
+	days := int64(frac)
+	if err := s.adjustDays(t, days, 1); err != nil {
+		return err
+	}
+	return s.adjustFractMicros(t, frac-float64(days), microsPerDay)
+}
+
+// adjustMonths adds the given number of months to the interval.
+func (s *decodeIntervalState) adjustMonths(t token, val int64) error {
+	var ok bool
+	if s.months, ok = addInt64(s.months, val); !ok || s.months > math.MaxInt32 || s.months < math.MinInt32 {
+		return s.outOfRange(t)
+	}
+	return nil
+}
+
+// adjustYears adds val+frac units of the given number of years to the
+// interval. Fractional years are rounded to the nearest month.
+func (s *decodeIntervalState) adjustYears(t token, val int64, frac float64, scale int64) error {
+	years, ok := mulInt64(val, scale)
+	if !ok {
+		return s.outOfRange(t)
+	}
+	if s.years, ok = addInt64(s.years, years); !ok || s.years > math.MaxInt32 || s.years < math.MinInt32 {
+		return s.outOfRange(t)
+	}
+	return s.adjustMonths(t, int64(math.RoundToEven(frac*float64(scale)*monthsPerYear)))
+}
+
+// addInt64 returns a+b, and whether the addition did not overflow.
+func addInt64(a, b int64) (int64, bool) {
+	sum := a + b
+	if (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// mulInt64 returns a*b, and whether the multiplication did not overflow.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	prod := a * b
+	if prod/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return prod, true
+}
+
+// FormatInterval formats the given interval as the given IntervalStyle.
+func FormatInterval(style IntervalStyle, iv Interval) string {
+	var b bytes.Buffer
+	WriteIntervalToBuffer(&b, style, iv)
+	return b.String()
+}
+
+// WriteIntervalToBuffer writes the given interval into the given buffer.
+// This is a port of PostgreSQL's EncodeInterval.
+func WriteIntervalToBuffer(buf *bytes.Buffer, style IntervalStyle, iv Interval) {
+	year := int64(iv.Months / monthsPerYear)
+	mon := int64(iv.Months % monthsPerYear)
+	mday := int64(iv.Days)
+	micros := iv.Micros
+	hour := micros / microsPerHour
+	micros -= hour * microsPerHour
+	min := micros / microsPerMinute
+	micros -= min * microsPerMinute
+	sec := micros / microsPerSecond
+	fsec := micros - sec*microsPerSecond
+
+	switch style {
+	case IntervalStyleSQLStandard:
+		hasNegative := year < 0 || mon < 0 || mday < 0 || hour < 0 || min < 0 || sec < 0 || fsec < 0
+		hasPositive := year > 0 || mon > 0 || mday > 0 || hour > 0 || min > 0 || sec > 0 || fsec > 0
+		hasYearMonth := year != 0 || mon != 0
+		hasDayTime := mday != 0 || hour != 0 || min != 0 || sec != 0 || fsec != 0
+		isSQLStandardValue := !(hasNegative && hasPositive) && !(hasYearMonth && hasDayTime)
+
+		// The SQL standard only allows a single sign preceding the whole
+		// interval, which cannot be used if the signs are mixed.
+		if hasNegative && isSQLStandardValue {
+			buf.WriteByte('-')
+			year, mon, mday, hour, min, sec, fsec = -year, -mon, -mday, -hour, -min, -sec, -fsec
+		}
+		switch {
+		case !hasNegative && !hasPositive:
+			buf.WriteByte('0')
+		case !isSQLStandardValue:
+			// Intervals which are not SQL standard values are written with
+			// an explicit sign on each part, to avoid ambiguity.
+			yearSign := signChar(year < 0 || mon < 0)
+			daySign := signChar(mday < 0)
+			secSign := signChar(hour < 0 || min < 0 || sec < 0 || fsec < 0)
+			fmt.Fprintf(
+				buf,
+				"%c%d-%d %c%d %c%d:%02d:",
+				yearSign, absInt64(year), absInt64(mon),
+				daySign, absInt64(mday),
+				secSign, absInt64(hour), absInt64(min),
+			)
+			writeIntervalSeconds(buf, sec, fsec, true)
+		case hasYearMonth:
+			fmt.Fprintf(buf, "%d-%d", year, mon)
+		case mday != 0:
+			fmt.Fprintf(buf, "%d %d:%02d:", mday, hour, min)
+			writeIntervalSeconds(buf, sec, fsec, true)
+		default:
+			fmt.Fprintf(buf, "%d:%02d:", hour, min)
+			writeIntervalSeconds(buf, sec, fsec, true)
+		}
+
+	case IntervalStyleISO8601:
+		if year == 0 && mon == 0 && mday == 0 && hour == 0 && min == 0 && sec == 0 && fsec == 0 {
+			buf.WriteString("PT0S")
+			return
+		}
+		buf.WriteByte('P')
+		writeISO8601IntervalPart(buf, year, 'Y')
+		writeISO8601IntervalPart(buf, mon, 'M')
+		writeISO8601IntervalPart(buf, mday, 'D')
+		if hour != 0 || min != 0 || sec != 0 || fsec != 0 {
+			buf.WriteByte('T')
+		}
+		writeISO8601IntervalPart(buf, hour, 'H')
+		writeISO8601IntervalPart(buf, min, 'M')
+		if sec != 0 || fsec != 0 {
+			if sec < 0 || fsec < 0 {
+				buf.WriteByte('-')
+			}
+			writeIntervalSeconds(buf, sec, fsec, false)
+			buf.WriteByte('S')
+		}
+
+	case IntervalStylePostgresVerbose:
+		isZero, isBefore := true, false
+		buf.WriteByte('@')
+		writeVerboseIntervalPart(buf, year, "year", &isZero, &isBefore)
+		writeVerboseIntervalPart(buf, mon, "mon", &isZero, &isBefore)
+		writeVerboseIntervalPart(buf, mday, "day", &isZero, &isBefore)
+		writeVerboseIntervalPart(buf, hour, "hour", &isZero, &isBefore)
+		writeVerboseIntervalPart(buf, min, "min", &isZero, &isBefore)
+		if sec != 0 || fsec != 0 {
+			buf.WriteByte(' ')
+			if sec < 0 || (sec == 0 && fsec < 0) {
+				if isZero {
+					isBefore = true
+				} else if !isBefore {
+					buf.WriteByte('-')
+				}
+			} else if isBefore {
+				buf.WriteByte('-')
+			}
+			writeIntervalSeconds(buf, sec, fsec, false)
+			buf.WriteString(" sec")
+			if absInt64(sec) != 1 || fsec != 0 {
+				buf.WriteByte('s')
+			}
+			isZero = false
+		}
+		if isZero {
+			buf.WriteString(" 0")
+		}
+		if isBefore {
+			buf.WriteString(" ago")
+		}
+
+	default:
+		isZero, isBefore := true, false
+		writePostgresIntervalPart(buf, year, "year", &isZero, &isBefore)
+		writePostgresIntervalPart(buf, mon, "mon", &isZero, &isBefore)
+		writePostgresIntervalPart(buf, mday, "day", &isZero, &isBefore)
+		if isZero || hour != 0 || min != 0 || sec != 0 || fsec != 0 {
+			if !isZero {
+				buf.WriteByte(' ')
+			}
+			if hour < 0 || min < 0 || sec < 0 || fsec < 0 {
+				buf.WriteByte('-')
+			} else if isBefore {
+				buf.WriteByte('+')
+			}
+			fmt.Fprintf(buf, "%02d:%02d:", absInt64(hour), absInt64(min))
+			writeIntervalSeconds(buf, sec, fsec, true)
+		}
+	}
+}
+
+// writeIntervalSeconds writes the absolute value of the given seconds and
+// microseconds, without trailing zeros in the fractional part. If
+// fillZeros is set, the seconds are padded to two digits.
+func writeIntervalSeconds(buf *bytes.Buffer, sec int64, fsec int64, fillZeros bool) {
+	if fillZeros {
+		fmt.Fprintf(buf, "%02d", absInt64(sec))
+	} else {
+		fmt.Fprintf(buf, "%d", absInt64(sec))
+	}
+	if fsec != 0 {
+		buf.WriteString(strings.TrimRight(fmt.Sprintf(".%06d", absInt64(fsec)), "0"))
+	}
+}
+
+// writeISO8601IntervalPart writes the given part of an interval in ISO 8601
+// format, omitting it if it is zero.
+func writeISO8601IntervalPart(buf *bytes.Buffer, value int64, unit byte) {
+	if value == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "%d%c", value, unit)
+}
+
+// writePostgresIntervalPart writes the given part of an interval in the
+// postgres style, omitting it if it is zero.
+func writePostgresIntervalPart(buf *bytes.Buffer, value int64, unit string, isZero, isBefore *bool) {
+	if value == 0 {
+		return
+	}
+	if !*isZero {
+		buf.WriteByte(' ')
+	}
+	if *isBefore && value > 0 {
+		buf.WriteByte('+')
+	}
+	fmt.Fprintf(buf, "%d %s", value, unit)
+	if value != 1 {
+		buf.WriteByte('s')
+	}
+	// Each non-zero part determines whether the next part needs an
+	// explicit sign.
+	*isBefore = value < 0
+	*isZero = false
+}
+
+// writeVerboseIntervalPart writes the given part of an interval in the
+// postgres_verbose style, omitting it if it is zero. The sign of the first
+// non-zero part determines whether the interval is written with "ago".
+func writeVerboseIntervalPart(buf *bytes.Buffer, value int64, unit string, isZero, isBefore *bool) {
+	if value == 0 {
+		return
+	}
+	if *isZero {
+		*isBefore = value < 0
+		value = absInt64(value)
+	} else if *isBefore {
+		value = -value
+	}
+	fmt.Fprintf(buf, " %d %s", value, unit)
+	if value != 1 {
+		buf.WriteByte('s')
+	}
+	*isZero = false
+}
+
+func signChar(negative bool) byte {
+	if negative {
+		return '-'
+	}
+	return '+'
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
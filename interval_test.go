@@ -0,0 +1,155 @@
+package pgdatetime
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/datadriven"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInterval(t *testing.T) {
+	datadriven.RunTest(t, "testdata/interval", func(t *testing.T, d *datadriven.TestData) string {
+		switch d.Cmd {
+		case "interval":
+			var opts []ParseOption
+			for _, arg := range d.CmdArgs {
+				switch strings.ToLower(arg.Key) {
+				case "intervalstyle":
+					style, err := ParseIntervalStyle(arg.Vals[0])
+					require.NoError(t, err)
+					opts = append(opts, WithIntervalStyle(style))
+				case "qualifier":
+					opts = append(opts, WithIntervalQualifier(parseIntervalQualifier(t, arg.Vals[0])))
+				case "precision":
+					var precision int
+					arg.Scan(t, 0, &precision)
+					opts = append(opts, WithIntervalPrecision(precision))
+				default:
+					t.Fatalf("unknown key: %s", arg.Key)
+				}
+			}
+			r, err := ParseInterval(d.Input, opts...)
+			require.NoError(t, err)
+			return fmt.Sprintf("%+v", r)
+		default:
+			t.Fatalf("command unknown: %s", d.Cmd)
+		}
+		return ""
+	})
+}
+
+// parseIntervalQualifier returns the IntervalQualifier with the given name,
+// e.g. DayToSecond.
+func parseIntervalQualifier(t *testing.T, s string) IntervalQualifier {
+	for q := IntervalQualifierFull; q <= IntervalQualifierMinuteToSecond; q++ {
+		if strings.EqualFold(q.String(), s) {
+			return q
+		}
+	}
+	t.Fatalf("unknown interval qualifier: %s", s)
+	return 0
+}
+
+func TestParseIntervalError(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"", NewParseError(0, "missing interval")},
+		{"1 fortnight", NewParseErrorf(2, "unknown unit: fortnight")},
+		{"day", NewParseError(0, "expected value before day")},
+		{"1 day hour", NewParseError(6, "expected value before hour")},
+		{"1 ago", NewParseError(0, "expected unit after 1")},
+		{"1 day ago 2 hours", NewParseError(6, "unexpected ago before end of interval")},
+		{"1 day 2 days", NewParseError(0, "duplicate field in interval: 1")},
+		{"1 day 12:00 13:00", NewParseError(6, "duplicate field in interval: 12:00")},
+		{"1 hour 12:00", NewParseError(0, "duplicate field in interval: 1")},
+		{"1.2.3 days", NewParseError(0, "invalid number in interval: 1.2.3")},
+		{"1-12", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 1-12")},
+		{"12:60", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 12:60")},
+		{"12:00:61", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 12:00:61")},
+		{"99999999999999999999 days", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 99999999999999999999")},
+		{"2147483648 days", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 2147483648")},
+		{"178956971 years", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "interval out of range")},
+		{"9223372036854775807 hours", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "interval field value out of range: 9223372036854775807")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseInterval(tc.s)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseIntervalQualifierError(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		q   IntervalQualifier
+		err error
+	}{
+		{"1 2", IntervalQualifierYearToMonth, NewParseError(0, "duplicate field in interval: 1")},
+		{"1 2", IntervalQualifierDayToMinute, NewParseError(0, "duplicate field in interval: 1")},
+		{"1 2", IntervalQualifierDayToSecond, NewParseError(0, "duplicate field in interval: 1")},
+		{"1:2 3", IntervalQualifierMinuteToSecond, NewParseError(0, "duplicate field in interval: 1:2")},
+	} {
+		t.Run(fmt.Sprintf("%s/%s", tc.q, tc.s), func(t *testing.T) {
+			_, err := ParseInterval(tc.s, WithIntervalQualifier(tc.q))
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseISO8601IntervalError(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"P", NewParseError(0, "invalid ISO 8601 duration: P")},
+		{"P1X", NewParseError(2, "invalid ISO 8601 duration: P1X")},
+		{"PT1D", NewParseError(3, "invalid ISO 8601 duration: PT1D")},
+		{"P1Y1-02-03", NewParseError(3, "invalid ISO 8601 duration: P1Y1-02-03")},
+		{"P0001-02-03X", NewParseError(11, "invalid ISO 8601 duration: P0001-02-03X")},
+		{"PT1H01:02", NewParseError(4, "invalid ISO 8601 duration: PT1H01:02")},
+		{"PY", NewParseError(1, `expected number in ISO 8601 duration, found "Y"`)},
+		{"P1e20Y", NewParseError(2, "invalid ISO 8601 duration: P1e20Y")},
+		{"P9999999999999999Y", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 1, "interval field value out of range: 9999999999999999")},
+		{"P999999999Y", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "interval out of range")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseInterval(tc.s)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+// TestFormatInterval tests formatting intervals works, and when formatted,
+// will re-parse to itself correctly.
+func TestFormatInterval(t *testing.T) {
+	datadriven.RunTest(t, "testdata/format_interval", func(t *testing.T, d *datadriven.TestData) string {
+		switch d.Cmd {
+		case "test":
+			iv, err := ParseInterval(d.Input)
+			require.NoError(t, err)
+
+			var ret strings.Builder
+			for _, style := range []IntervalStyle{
+				IntervalStylePostgres,
+				IntervalStylePostgresVerbose,
+				IntervalStyleSQLStandard,
+				IntervalStyleISO8601,
+			} {
+				formatted := FormatInterval(style, iv)
+				fmt.Fprintf(&ret, "%s: %s\n", style, formatted)
+
+				r, err := ParseInterval(formatted, WithIntervalStyle(style))
+				require.NoError(t, err, "parsing %s", formatted)
+				require.Equal(t, iv, r, "parsing %s", formatted)
+			}
+			return ret.String()
+		default:
+			t.Fatalf("command unknown: %s", d.Cmd)
+		}
+		return ""
+	})
+}
@@ -0,0 +1,67 @@
+package pgdatetime
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TimeToJulianDay returns the Julian day number of the date of the given time
+// in its location, as PostgreSQL's date2j does.
+func TimeToJulianDay(t time.Time) int {
+	year, month, day := t.Date()
+	return dateToJulianDay(year, int(month), day)
+}
+
+// JulianDayToTime returns midnight of the date with the given Julian day
+// number in the given location, as PostgreSQL's j2date does. As in
+// PostgreSQL, the Julian day number must be between 0 and math.MaxInt32.
+func JulianDayToTime(jd int, loc *time.Location) (time.Time, error) {
+	if jd < 0 || jd > math.MaxInt32 {
+		return time.Time{}, fmt.Errorf("julian day out of range: %d", jd)
+	}
+	year, month, day := julianDayToDate(jd)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc), nil
+}
+
+// dateToJulianDay converts a date to a Julian day number. As with time.Time,
+// 1 BC is year 0, 2 BC is year -1 and so on.
+// This is a port of PostgreSQL's date2j.
+func dateToJulianDay(year, month, day int) int {
+	if month > 2 {
+		month++
+		year += 4800
+	} else {
+		month += 13
+		year += 4799
+	}
+	century := year / 100
+	julian := year*365 - 32167
+	julian += year/4 - century + century/4
+	julian += 7834*month/256 + day
+	return julian
+}
+
+// julianDayToDate converts a Julian day number to a date. The Julian day
+// number must be between 0 and math.MaxInt32.
+// This is a port of PostgreSQL's j2date.
+func julianDayToDate(jd int) (year, month, day int) {
+	julian := uint64(jd) + 32044
+	quad := julian / 146097
+	extra := (julian-quad*146097)*4 + 3
+	julian += 60 + quad*3 + extra/146097
+	quad = julian / 1461
+	julian -= quad * 1461
+	y := julian * 4 / 1461
+	if y != 0 {
+		julian = (julian+305)%365 + 123
+	} else {
+		julian = (julian+306)%366 + 123
+	}
+	y += quad * 4
+	year = int(y) - 4800
+	quad = julian * 2141 / 65536
+	day = int(julian - 7834*quad/256)
+	month = int((quad+10)%12 + 1)
+	return year, month, day
+}
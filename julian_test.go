@@ -0,0 +1,49 @@
+package pgdatetime
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJulianDay(t *testing.T) {
+	for _, tc := range []struct {
+		t  time.Time
+		jd int
+	}{
+		{time.Date(-4713, time.November, 24, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(-4712, time.January, 1, 0, 0, 0, 0, time.UTC), 38},
+		{time.Date(0, time.December, 31, 0, 0, 0, 0, time.UTC), 1721425},
+		{time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC), 1721426},
+		{time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC), 2440588},
+		{time.Date(1999, time.January, 8, 0, 0, 0, 0, time.UTC), 2451187},
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), 2451545},
+		{time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC), 2451604},
+		{time.Date(294276, time.December, 31, 0, 0, 0, 0, time.UTC), 109203527},
+	} {
+		t.Run(tc.t.String(), func(t *testing.T) {
+			require.Equal(t, tc.jd, TimeToJulianDay(tc.t))
+			got, err := JulianDayToTime(tc.jd, time.UTC)
+			require.NoError(t, err)
+			require.Equal(t, tc.t, got)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		for jd := 0; jd < 3000000; jd += 17 {
+			got, err := JulianDayToTime(jd, time.UTC)
+			require.NoError(t, err)
+			require.Equal(t, jd, TimeToJulianDay(got))
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		for _, jd := range []int{-1, -100000, math.MaxInt32 + 1} {
+			_, err := JulianDayToTime(jd, time.UTC)
+			require.EqualError(t, err, fmt.Sprintf("julian day out of range: %d", jd))
+		}
+	})
+}
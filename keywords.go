@@ -0,0 +1,168 @@
+package pgdatetime
+
+// keyword is a reserved word which can appear in datetime input.
+type keyword struct {
+	// typ is the Component the keyword represents.
+	typ Component
+	// val is the value associated with the keyword, if any.
+	val int
+}
+
+// Values of keywords of type ComponentAMPM.
+const (
+	meridianAM = iota + 1
+	meridianPM
+)
+
+// Values of keywords of type ComponentADBC.
+const (
+	eraAD = iota
+	eraBC
+)
+
+// keywords contains all reserved words, keyed by their lower-cased value.
+var keywords = map[string]keyword{
+	"-infinity": {typ: ComponentEarly},
+	"+infinity": {typ: ComponentLate},
+	"allballs":  {typ: ComponentZulu},
+	"epoch":     {typ: ComponentEpoch},
+	"infinity":  {typ: ComponentLate},
+	"now":       {typ: ComponentNow},
+	"today":     {typ: ComponentToday},
+	"tomorrow":  {typ: ComponentTomorrow},
+	"yesterday": {typ: ComponentYesterday},
+
+	"jan":       {typ: ComponentMonth, val: 1},
+	"january":   {typ: ComponentMonth, val: 1},
+	"feb":       {typ: ComponentMonth, val: 2},
+	"february":  {typ: ComponentMonth, val: 2},
+	"mar":       {typ: ComponentMonth, val: 3},
+	"march":     {typ: ComponentMonth, val: 3},
+	"apr":       {typ: ComponentMonth, val: 4},
+	"april":     {typ: ComponentMonth, val: 4},
+	"may":       {typ: ComponentMonth, val: 5},
+	"jun":       {typ: ComponentMonth, val: 6},
+	"june":      {typ: ComponentMonth, val: 6},
+	"jul":       {typ: ComponentMonth, val: 7},
+	"july":      {typ: ComponentMonth, val: 7},
+	"aug":       {typ: ComponentMonth, val: 8},
+	"august":    {typ: ComponentMonth, val: 8},
+	"sep":       {typ: ComponentMonth, val: 9},
+	"sept":      {typ: ComponentMonth, val: 9},
+	"september": {typ: ComponentMonth, val: 9},
+	"oct":       {typ: ComponentMonth, val: 10},
+	"october":   {typ: ComponentMonth, val: 10},
+	"nov":       {typ: ComponentMonth, val: 11},
+	"november":  {typ: ComponentMonth, val: 11},
+	"dec":       {typ: ComponentMonth, val: 12},
+	"december":  {typ: ComponentMonth, val: 12},
+
+	"sun":       {typ: ComponentDOW, val: 0},
+	"sunday":    {typ: ComponentDOW, val: 0},
+	"mon":       {typ: ComponentDOW, val: 1},
+	"monday":    {typ: ComponentDOW, val: 1},
+	"tue":       {typ: ComponentDOW, val: 2},
+	"tues":      {typ: ComponentDOW, val: 2},
+	"tuesday":   {typ: ComponentDOW, val: 2},
+	"wed":       {typ: ComponentDOW, val: 3},
+	"weds":      {typ: ComponentDOW, val: 3},
+	"wednesday": {typ: ComponentDOW, val: 3},
+	"thu":       {typ: ComponentDOW, val: 4},
+	"thur":      {typ: ComponentDOW, val: 4},
+	"thurs":     {typ: ComponentDOW, val: 4},
+	"thursday":  {typ: ComponentDOW, val: 4},
+	"fri":       {typ: ComponentDOW, val: 5},
+	"friday":    {typ: ComponentDOW, val: 5},
+	"sat":       {typ: ComponentDOW, val: 6},
+	"saturday":  {typ: ComponentDOW, val: 6},
+
+	"am": {typ: ComponentAMPM, val: meridianAM},
+	"pm": {typ: ComponentAMPM, val: meridianPM},
+
+	"ad": {typ: ComponentADBC, val: eraAD},
+	"bc": {typ: ComponentADBC, val: eraBC},
+
+	// Units, which apply to the value that follows them.
+	"j":      {typ: ComponentJulian},
+	"jd":     {typ: ComponentJulian},
+	"julian": {typ: ComponentJulian},
+	"t":      {typ: ComponentTime},
+
+	// Noise words, which are ignored.
+	"at": {typ: ComponentString},
+	"on": {typ: ComponentString},
+}
+
+// intervalUnits contains the words which can appear in interval input, keyed
+// by their lower-cased value.
+var intervalUnits = map[string]keyword{
+	"ago": {typ: ComponentAgo},
+
+	"c":         {typ: ComponentCentury},
+	"cent":      {typ: ComponentCentury},
+	"centuries": {typ: ComponentCentury},
+	"century":   {typ: ComponentCentury},
+
+	"d":    {typ: ComponentDay},
+	"day":  {typ: ComponentDay},
+	"days": {typ: ComponentDay},
+
+	"dec":     {typ: ComponentDecade},
+	"decade":  {typ: ComponentDecade},
+	"decades": {typ: ComponentDecade},
+	"decs":    {typ: ComponentDecade},
+
+	"h":     {typ: ComponentHour},
+	"hour":  {typ: ComponentHour},
+	"hours": {typ: ComponentHour},
+	"hr":    {typ: ComponentHour},
+	"hrs":   {typ: ComponentHour},
+
+	"m":       {typ: ComponentMinute},
+	"min":     {typ: ComponentMinute},
+	"mins":    {typ: ComponentMinute},
+	"minute":  {typ: ComponentMinute},
+	"minutes": {typ: ComponentMinute},
+
+	"mon":    {typ: ComponentMonth},
+	"mons":   {typ: ComponentMonth},
+	"month":  {typ: ComponentMonth},
+	"months": {typ: ComponentMonth},
+
+	"mil":        {typ: ComponentMillennium},
+	"millennia":  {typ: ComponentMillennium},
+	"millennium": {typ: ComponentMillennium},
+	"mils":       {typ: ComponentMillennium},
+
+	"ms":           {typ: ComponentMillis},
+	"msec":         {typ: ComponentMillis},
+	"msecs":        {typ: ComponentMillis},
+	"mseconds":     {typ: ComponentMillis},
+	"millisecon":   {typ: ComponentMillis},
+	"millisecond":  {typ: ComponentMillis},
+	"milliseconds": {typ: ComponentMillis},
+
+	"s":       {typ: ComponentSecond},
+	"sec":     {typ: ComponentSecond},
+	"secs":    {typ: ComponentSecond},
+	"second":  {typ: ComponentSecond},
+	"seconds": {typ: ComponentSecond},
+
+	"us":           {typ: ComponentMicros},
+	"usec":         {typ: ComponentMicros},
+	"usecs":        {typ: ComponentMicros},
+	"useconds":     {typ: ComponentMicros},
+	"microsecon":   {typ: ComponentMicros},
+	"microsecond":  {typ: ComponentMicros},
+	"microseconds": {typ: ComponentMicros},
+
+	"w":     {typ: ComponentWeek},
+	"week":  {typ: ComponentWeek},
+	"weeks": {typ: ComponentWeek},
+
+	"y":     {typ: ComponentYear},
+	"year":  {typ: ComponentYear},
+	"years": {typ: ComponentYear},
+	"yr":    {typ: ComponentYear},
+	"yrs":   {typ: ComponentYear},
+}
@@ -0,0 +1,144 @@
+package pgdatetime
+
+// defaultTwoDigitYearPivot is the pivot PostgreSQL uses for two-digit years,
+// i.e. 00-69 are read as 2000-2069 and 70-99 as 1970-1999.
+const defaultTwoDigitYearPivot = 70
+
+// ParseOption configures how input is parsed.
+type ParseOption func(*parseOptions)
+
+// parseOptions contains the settings configured by ParseOption.
+type parseOptions struct {
+	twoDigitYearPivot        int
+	rejectTwoDigitYears      bool
+	intervalStyle            IntervalStyle
+	intervalQualifier        IntervalQualifier
+	intervalPrecision        int
+	zoneResolver             ZoneResolver
+	abbrevs                  *TimeZoneAbbreviations
+	rejectDSTTransitionTimes bool
+}
+
+func defaultParseOptions() parseOptions {
+	return parseOptions{
+		twoDigitYearPivot: defaultTwoDigitYearPivot,
+		intervalStyle:     DefaultIntervalStyle(),
+		intervalPrecision: -1,
+	}
+}
+
+func makeParseOptions(opts []ParseOption) parseOptions {
+	o := defaultParseOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTwoDigitYearPivot sets the pivot used to interpret two-digit years.
+// Years below the pivot are read as 20xx, and years at or above it as 19xx.
+// PostgreSQL uses 70. Pivots below 0 or above 100 are clamped to 0 and 100
+// respectively, i.e. all two-digit years are read as 19xx or 20xx.
+func WithTwoDigitYearPivot(pivot int) ParseOption {
+	if pivot < 0 {
+		pivot = 0
+	} else if pivot > 100 {
+		pivot = 100
+	}
+	return func(o *parseOptions) {
+		o.twoDigitYearPivot = pivot
+	}
+}
+
+// WithRejectTwoDigitYears causes input containing a two-digit year to be
+// rejected instead of being interpreted relative to the pivot.
+func WithRejectTwoDigitYears() ParseOption {
+	return func(o *parseOptions) {
+		o.rejectTwoDigitYears = true
+	}
+}
+
+// WithIntervalStyle sets the IntervalStyle used when parsing intervals. In
+// the SQL standard style, a leading minus sign applies to all fields of an
+// interval unless other fields have explicit signs, e.g. "-1 2:03:04" is
+// read as -1 days -2:03:04.
+func WithIntervalStyle(style IntervalStyle) ParseOption {
+	return func(o *parseOptions) {
+		o.intervalStyle = style
+	}
+}
+
+// WithIntervalQualifier sets the fields allowed when parsing intervals, as
+// given by the qualifier of a SQL interval type. Numbers without a unit are
+// read as the last allowed field, e.g. "1 2" is one day and two hours with
+// IntervalQualifierDayToHour, and fields smaller than the last allowed
+// field are truncated.
+func WithIntervalQualifier(q IntervalQualifier) ParseOption {
+	return func(o *parseOptions) {
+		o.intervalQualifier = q
+	}
+}
+
+// WithIntervalPrecision sets the number of fractional digits of seconds
+// kept when parsing intervals, rounding the seconds to the given precision.
+// As in PostgreSQL, a precision above 6 is reduced to 6, and a precision
+// below 0 is increased to 0.
+func WithIntervalPrecision(precision int) ParseOption {
+	if precision < 0 {
+		precision = 0
+	} else if precision > maxIntervalPrecision {
+		precision = maxIntervalPrecision
+	}
+	return func(o *parseOptions) {
+		o.intervalPrecision = precision
+	}
+}
+
+// WithTimeZoneAbbreviations sets the time zone abbreviations recognized when
+// parsing, as with PostgreSQL's timezone_abbreviations setting. By default,
+// the abbreviations of the ZoneResolver are used. Time zone names are still
+// loaded using the ZoneResolver.
+func WithTimeZoneAbbreviations(a *TimeZoneAbbreviations) ParseOption {
+	return func(o *parseOptions) {
+		o.abbrevs = a
+	}
+}
+
+// WithZoneResolver sets the ZoneResolver used to look up time zone names
+// and abbreviations when parsing, overriding the ZoneResolver of the
+// DateStyle. By default, the ZoneResolver of the DateStyle is used, or
+// DefaultZoneResolver if it is nil.
+func WithZoneResolver(r ZoneResolver) ParseOption {
+	return func(o *parseOptions) {
+		o.zoneResolver = r
+	}
+}
+
+// WithRejectDSTTransitionTimes causes input with a wall clock time which
+// does not exist or is ambiguous in its time zone, e.g. due to daylight
+// saving time, to be rejected instead of being resolved as PostgreSQL does.
+// PostgreSQL reads a time which does not exist using the offset before the
+// transition, and an ambiguous time using the offset after the transition.
+func WithRejectDSTTransitionTimes() ParseOption {
+	return func(o *parseOptions) {
+		o.rejectDSTTransitionTimes = true
+	}
+}
+
+// resolver returns the ZoneResolver to use when parsing, which is the one
+// given with WithZoneResolver, or otherwise the given fallback, or
+// DefaultZoneResolver if that is nil. Abbreviations given with
+// WithTimeZoneAbbreviations take precedence over those of the ZoneResolver.
+func (o parseOptions) resolver(fallback ZoneResolver) ZoneResolver {
+	r := o.zoneResolver
+	if r == nil {
+		r = fallback
+	}
+	if r == nil {
+		r = DefaultZoneResolver()
+	}
+	if o.abbrevs != nil {
+		r = abbreviationsZoneResolver{ZoneResolver: r, abbrevs: o.abbrevs}
+	}
+	return r
+}
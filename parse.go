@@ -2,6 +2,7 @@ package pgdatetime
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -29,12 +30,15 @@ const (
 // token represents a date token Component of a datetime.
 type token struct {
 	tokenType tokenType
-	val       string
-	idx       int
+	// val is the lower-cased value of the token.
+	val string
+	// raw is the value of the token as it appeared in the input,
+	// which is required to resolve case sensitive time zone names.
+	raw string
+	idx int
 }
 
 func tokenizeDateTime(s string) ([]token, error) {
-	s = strings.ToLower(s)
 	i := 0
 	ret := []token{}
 	isDigit := func(b byte) bool {
@@ -54,7 +58,7 @@ func tokenizeDateTime(s string) ([]token, error) {
 	appendToken := func(t tokenType, start int) {
 		ret = append(
 			ret,
-			token{tokenType: t, val: s[start:i], idx: start},
+			token{tokenType: t, val: strings.ToLower(s[start:i]), raw: s[start:i], idx: start},
 		)
 	}
 
@@ -125,11 +129,19 @@ func tokenizeDateTime(s string) ([]token, error) {
 			advanceWhen(isLetter)
 
 			t := tokenTypeString
-			// Could be a date with a leading text month.
-			if i < len(s) && (s[i] == '-' || s[i] == '/' || s[i] == '.') {
-				delimiter := s[i]
+			// Could be a date with a leading text month, or a time zone name
+			// with embedded punctuation (e.g. America/New_York).
+			// If the next character is a digit or '+', this could also be a
+			// time zone name (e.g. EST5EDT) unless what we have read so far
+			// is a keyword.
+			isDate := i < len(s) && (s[i] == '-' || s[i] == '/' || s[i] == '.')
+			if i < len(s) && (s[i] == '+' || isDigit(s[i])) {
+				_, isKeyword := keywords[strings.ToLower(s[start:i])]
+				isDate = !isKeyword
+			}
+			if isDate {
 				advanceWhen(func(b byte) bool {
-					return isDigit(b) || b == delimiter
+					return isLetterOrDigit(b) || strings.IndexByte("+-/_.:", b) != -1
 				})
 				t = tokenTypeDate
 			}
@@ -145,8 +157,10 @@ func tokenizeDateTime(s string) ([]token, error) {
 			}
 			switch {
 			case isDigit(s[i]):
+				// This could also be a signed number, or a signed year-month
+				// interval, e.g. -1-2.
 				advanceWhen(func(b byte) bool {
-					return isDigit(b) || b == ':' || b == '.'
+					return isDigit(b) || b == ':' || b == '.' || b == '-'
 				})
 				appendToken(tokenTypeTZ, start)
 			case isLetter(s[i]):
@@ -165,16 +179,93 @@ func tokenizeDateTime(s string) ([]token, error) {
 	return ret, nil
 }
 
+// removeSpaces removes all whitespace from the given string.
+func removeSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+var (
+	// minTimestamp is the earliest timestamp supported by PostgreSQL,
+	// 4714-11-24 00:00:00 UTC BC.
+	minTimestamp = time.Date(-4713, time.November, 24, 0, 0, 0, 0, time.UTC)
+	// endTimestamp is the first timestamp after the latest timestamp
+	// supported by PostgreSQL, 294276-12-31 23:59:59.999999 UTC.
+	endTimestamp = time.Date(294277, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// endDateJulianDay is the Julian day of the first date after the latest date
+// supported by PostgreSQL, 5874897-12-31, and maxDateYear is its year.
+const (
+	endDateJulianDay = 2147483494
+	maxDateYear      = 5874897
+)
+
+// valueType is the type of value being decoded.
+type valueType int
+
+const (
+	valueTypeTimestampTZ valueType = iota
+	valueTypeTimestamp
+	valueTypeDate
+	valueTypeTime
+	valueTypeTimeTZ
+)
+
 type decodeTokenState struct {
+	valueType                   valueType
 	seen                        Component
 	year, month, day            int
 	hour, minute, second, nanos int
 	loc                         *time.Location
 	typ                         ParseResultType
 	is2DigitYear                bool
+	haveTextMonth               bool
+	// meridian is set to meridianAM or meridianPM if the time is specified
+	// in 12 hour format, with meridianIdx being the index of the token.
+	meridian, meridianIdx int
+	// isBC is set if the year is specified with a BC era.
+	isBC bool
+	// yearIdx, monthIdx, dayIdx and timeIdx are the indexes of the tokens
+	// the respective fields were read from.
+	yearIdx, monthIdx, dayIdx, timeIdx int
+	// dayOfYear is set if the date was specified as a year and day of the
+	// year, e.g. 1999.008.
+	dayOfYear int
+	// isJulian is set if the date was specified as a Julian day.
+	isJulian bool
+	// prefix is set if the previous token was a unit which applies to the
+	// next token, e.g. J for Julian days. prefixToken is the unit token.
+	prefix      Component
+	prefixToken token
+	// special is set if the input is one of the special values epoch,
+	// infinity or -infinity.
+	special Component
+	// namedZone is set to the token of the time zone if it was specified by
+	// name, e.g. America/New_York, rather than by offset or abbreviation.
+	namedZone *token
+	// dynamicAbbreviation is set to the token of the time zone if it was
+	// specified by an abbreviation whose offset depends on the date, e.g.
+	// MSK, in which case loc is the time zone the abbreviation refers to.
+	dynamicAbbreviation *token
+	// leadingDateIdx is the index of the only token which may be decoded as
+	// a date when decoding a time of day, or -1 if there is none.
+	leadingDateIdx int
 
 	dateStyle DateStyle
 	now       time.Time
+	opts      parseOptions
+	// zoneResolver is used to look up time zone names and abbreviations.
+	zoneResolver ZoneResolver
+}
+
+// isTimeOnly returns whether a time of day is being decoded.
+func (s *decodeTokenState) isTimeOnly() bool {
+	return s.valueType == valueTypeTime || s.valueType == valueTypeTimeTZ
 }
 
 func (s *decodeTokenState) hasSeen(c Component) bool {
@@ -186,35 +277,158 @@ func (s *decodeTokenState) markSeen(c Component) {
 }
 
 func (s *decodeTokenState) decodeDate(t token) error {
-	if s.hasSeen(ComponentMonth | ComponentDay) {
-		// If we've already seen the month and day, this could be a timezone.
-		return nil
+	if s.prefix == ComponentJulian {
+		return s.decodeJulianDayWithTimeZone(t)
 	}
 
-	delimiterIdx := strings.IndexAny(t.val, "/-.")
-	if delimiterIdx == -1 {
-		return NewParseError(t.idx, "expected date separator but found none")
+	// Dates containing only letters and dots are abbreviations, e.g. "Jan.".
+	if strings.Trim(t.val, "abcdefghijklmnopqrstuvwxyz.") == "" {
+		return s.decodeString(token{
+			tokenType: tokenTypeString,
+			val:       strings.ReplaceAll(t.val, ".", ""),
+			raw:       strings.ReplaceAll(t.raw, ".", ""),
+			idx:       t.idx,
+		})
 	}
-	fields := strings.Split(t.val, t.val[delimiterIdx:delimiterIdx+1])
-	// TODO: text month
-	currLen := 0
+
+	// If we've already seen the month and day, this is either a
+	// concatenated time with a trailing time zone (e.g. 040506-08), or a time
+	// zone name with embedded punctuation (e.g. America/New_York). We also
+	// allow time zone names before the date.
+	// When decoding a time of day, only a leading date is allowed, as in
+	// "1999-01-08 04:05:06".
+	isTimeField := s.isTimeOnly() && t.idx != s.leadingDateIdx
+	if s.prefix != 0 || s.hasSeen(ComponentMonth|ComponentDay) || isTimeField {
+		if s.prefix != 0 || unicode.IsDigit(rune(t.val[0])) {
+			return s.decodeNumberFieldWithTimeZone(t)
+		}
+		if loc, ok := s.zoneResolver.LoadLocation(t.raw); ok {
+			return s.setNamedLocation(t, loc)
+		}
+		return NewParseErrorWithKindf(ParseErrorKindUnknownTimeZone, t.idx, "time zone not recognized: %s", t.raw)
+	}
+	if !unicode.IsDigit(rune(t.val[0])) {
+		if loc, ok := s.zoneResolver.LoadLocation(t.raw); ok {
+			return s.setNamedLocation(t, loc)
+		}
+	}
+
+	// Split the date into runs of digits or letters, ignoring any
+	// delimiters in between.
+	var fields []token
+	for i := 0; i < len(t.val); {
+		start := i
+		switch {
+		case unicode.IsDigit(rune(t.val[i])):
+			for i < len(t.val) && unicode.IsDigit(rune(t.val[i])) {
+				i++
+			}
+		case unicode.IsLetter(rune(t.val[i])):
+			for i < len(t.val) && unicode.IsLetter(rune(t.val[i])) {
+				i++
+			}
+		default:
+			i++
+			continue
+		}
+		fields = append(fields, token{val: t.val[start:i], raw: t.raw[start:i], idx: t.idx + start})
+	}
+
+	// Look at text fields first, since they are unambiguously months.
 	for _, field := range fields {
-		if err := s.decodeNumber(token{val: field, idx: t.idx + currLen}); err != nil {
+		if !unicode.IsLetter(rune(field.val[0])) {
+			continue
+		}
+		kw, ok := keywords[field.val]
+		if !ok || kw.typ != ComponentMonth {
+			return NewParseErrorf(field.idx, "unexpected text in date: %s", field.raw)
+		}
+		if s.hasSeen(ComponentMonth) {
+			return NewParseErrorf(field.idx, "duplicate month: %s", field.raw)
+		}
+		s.markSeen(ComponentMonth)
+		s.month = kw.val
+		s.monthIdx = field.idx
+		s.haveTextMonth = true
+	}
+	for _, field := range fields {
+		if unicode.IsLetter(rune(field.val[0])) {
+			continue
+		}
+		if err := s.decodeNumber(field); err != nil {
 			return err
 		}
-		currLen += len(field) + 1
+	}
+	if !s.hasSeen(ComponentDateMask) {
+		return NewParseErrorf(t.idx, "incomplete date: %s", t.val)
 	}
 	return nil
 }
 
+// decodeNumberToken decodes a token consisting of a number.
+func (s *decodeTokenState) decodeNumberToken(t token) error {
+	if s.prefix != 0 {
+		return s.decodePrefixedNumber(t)
+	}
+	dotIdx := strings.IndexByte(t.val, '.')
+	switch {
+	case dotIdx == 0:
+		// A standalone fractional second following a time, e.g. 15:16 .5.
+		if s.seen&ComponentTimeMask == 0 {
+			return NewParseErrorf(t.idx, "unexpected fractional second: %s", t.val)
+		}
+		if err := s.markSeenUnique(t, ComponentMicros); err != nil {
+			return err
+		}
+		return s.decodeFractionalSecond(t)
+	case s.isTimeOnly():
+		// Numbers can only be concatenated times when decoding a time of
+		// day, e.g. 040506 or 0405.
+		return s.decodeNumberField(t, s.seen|ComponentDateMask)
+	case dotIdx != -1 && s.seen&ComponentDateMask == 0:
+		// A date with an embedded decimal, e.g. 1999.008.
+		return s.decodeDate(t)
+	case dotIdx > 2:
+		// A concatenated date or time, e.g. 040506.789.
+		return s.decodeNumberField(t, s.seen)
+	case len(t.val) >= 6 && (s.seen&ComponentDateMask == 0 || s.seen&ComponentTimeMask == 0):
+		// A concatenated date or time, e.g. 19990108 or 040506. Years with
+		// six or more digits must come after both the date and time, or be
+		// part of a delimited date.
+		return s.decodeNumberField(t, s.seen)
+	default:
+		return s.decodeNumber(t)
+	}
+}
+
+// decodeNumber decodes a single date or time field.
 func (s *decodeTokenState) decodeNumber(t token) error {
 	i := 0
 	num, err := s.readDigits(t, &i)
 	if err != nil {
 		return err
 	}
-	// TODO: decimal point
-	// TODO: day of year
+	if i < len(t.val) {
+		if t.val[i] != '.' {
+			return NewParseErrorf(t.idx+i, "unexpected character: %c", t.val[i])
+		}
+		// More than two digits before the decimal point can only be a
+		// concatenated date or time, e.g. 2001.360 or 040506.789.
+		if i > 2 {
+			return s.decodeNumberField(t, s.seen|ComponentDateMask)
+		}
+		if err := s.decodeFractionalSecond(token{val: t.val[i:], idx: t.idx + i}); err != nil {
+			return err
+		}
+	}
+
+	// A three digit number after the year is the day of the year.
+	if i == 3 && s.seen&ComponentDateMask == ComponentYear && num >= 1 && num <= 366 {
+		s.markSeen(ComponentDOY | ComponentMonth | ComponentDay)
+		s.dayOfYear = num
+		return nil
+	}
+
 	var seenMask Component
 	switch s.seen & ComponentDateMask {
 	case 0:
@@ -235,15 +449,36 @@ func (s *decodeTokenState) decodeNumber(t token) error {
 		seenMask |= ComponentMonth
 		s.month = num
 	case ComponentMonth:
-		// TODO: check text month
-		// Must be at second field of MM-DD-YYYY
-		seenMask |= ComponentDay
-		s.day = num
+		if s.haveTextMonth {
+			// We are at the first numeric field of a date with a text month.
+			// MON-DD-YYYY, DD-MON-YYYY and YYYY-MON-DD are unambiguous, and
+			// MON-DD-YY or DD-MON-YY are accepted unless we are in YMD
+			// mode, in which case it is read as YY-MON-DD.
+			if len(t.val) >= 3 || s.dateStyle.Order == OrderYMD {
+				seenMask |= ComponentYear
+				s.year = num
+			} else {
+				seenMask |= ComponentDay
+				s.day = num
+			}
+		} else {
+			// Must be at second field of MM-DD-YYYY
+			seenMask |= ComponentDay
+			s.day = num
+		}
 	case ComponentYear | ComponentMonth:
-		// TODO: check text month
-		// Must be at third field of YYYY-MM-DD.
-		seenMask |= ComponentDay
-		s.day = num
+		if s.haveTextMonth && len(t.val) >= 3 && s.is2DigitYear {
+			// Accept DD-MON-YYYY even in YMD mode, by treating the year we
+			// have already seen as a day.
+			seenMask |= ComponentDay
+			s.day = s.year
+			s.year = num
+			s.is2DigitYear = false
+		} else {
+			// Must be at third field of YYYY-MM-DD.
+			seenMask |= ComponentDay
+			s.day = num
+		}
 	case ComponentDay:
 		// Must be at second field of DD-MM-YYYY.
 		seenMask |= ComponentMonth
@@ -253,33 +488,188 @@ func (s *decodeTokenState) decodeNumber(t token) error {
 		seenMask |= ComponentYear
 		s.year = num
 	case ComponentDay | ComponentMonth | ComponentYear:
-		// TODO: have all three so it is time related.
+		// We have the whole date, so this must be a time.
+		return s.decodeNumberField(t, s.seen)
+	default:
+		return NewParseErrorf(t.idx, "unexpected number: %s", t.val)
 	}
-	if len(t.val) == 2 && seenMask == ComponentYear {
-		s.is2DigitYear = true
+	switch seenMask {
+	case ComponentYear:
+		s.is2DigitYear = len(t.val) <= 2
+		s.yearIdx = t.idx
+	case ComponentMonth:
+		s.monthIdx = t.idx
+	case ComponentDay:
+		s.dayIdx = t.idx
 	}
 	s.seen |= seenMask
 	return nil
 }
 
+// decodeNumberField decodes a concatenated date or time, e.g. 19990108,
+// 990108, 040506 or 0405. seen is the set of Components to assume have already
+// been seen.
+func (s *decodeTokenState) decodeNumberField(t token, seen Component) error {
+	val := t.val
+	if dotIdx := strings.IndexByte(val, '.'); dotIdx != -1 {
+		// A decimal point can only be in a time with fractional seconds.
+		if err := s.decodeFractionalSecond(token{val: val[dotIdx:], idx: t.idx + dotIdx}); err != nil {
+			return err
+		}
+		val = val[:dotIdx]
+	} else if seen&ComponentDateMask != ComponentDateMask && len(val) >= 6 {
+		// The last two digits are the day, the two before that are the
+		// month and the rest are the year.
+		if err := s.markSeenUnique(t, ComponentDateMask); err != nil {
+			return err
+		}
+		s.day, _ = strconv.Atoi(val[len(val)-2:])
+		s.month, _ = strconv.Atoi(val[len(val)-4 : len(val)-2])
+		s.year, _ = strconv.Atoi(val[:len(val)-4])
+		s.is2DigitYear = len(val) == 6
+		s.yearIdx, s.monthIdx, s.dayIdx = t.idx, t.idx, t.idx
+		return nil
+	}
+	if seen&ComponentTimeMask != ComponentTimeMask && (len(val) == 6 || len(val) == 4) {
+		// hhmmss or hhmm.
+		if err := s.markSeenUnique(t, ComponentTimeMask); err != nil {
+			return err
+		}
+		s.timeIdx = t.idx
+		s.hour, _ = strconv.Atoi(val[:2])
+		s.minute, _ = strconv.Atoi(val[2:4])
+		s.second = 0
+		if len(val) == 6 {
+			s.second, _ = strconv.Atoi(val[4:])
+		}
+		return nil
+	}
+	return NewParseErrorf(t.idx, "unexpected number: %s", t.val)
+}
+
+// decodeNumberFieldWithTimeZone decodes a concatenated time with a trailing
+// time zone, e.g. 040506-08.
+func (s *decodeTokenState) decodeNumberFieldWithTimeZone(t token) error {
+	if s.prefix != 0 && s.prefix != ComponentTime {
+		return NewParseErrorf(s.prefixToken.idx, "unexpected unit: %s", s.prefixToken.raw)
+	}
+	s.prefix = 0
+	if s.hasSeen(ComponentTimeMask) {
+		return NewParseErrorf(t.idx, "duplicate time Component: %s", t.val)
+	}
+	dashIdx := strings.IndexByte(t.val, '-')
+	if dashIdx == -1 {
+		return NewParseErrorf(t.idx, "expected time zone after time: %s", t.val)
+	}
+	if err := s.decodeTimeZone(token{tokenType: tokenTypeTZ, val: t.val[dashIdx:], raw: t.raw[dashIdx:], idx: t.idx + dashIdx}); err != nil {
+		return err
+	}
+	seen := s.seen
+	if s.isTimeOnly() {
+		seen |= ComponentDateMask
+	}
+	return s.decodeNumberField(token{val: t.val[:dashIdx], raw: t.raw[:dashIdx], idx: t.idx}, seen)
+}
+
+// decodePrefixedNumber decodes a number preceded by a unit, e.g. J2451187.
+func (s *decodeTokenState) decodePrefixedNumber(t token) error {
+	prefix := s.prefix
+	s.prefix = 0
+	i := 0
+	num, err := s.readDigits(t, &i)
+	if err != nil {
+		return err
+	}
+	frac := t.val[i:]
+	if frac != "" && (frac[0] != '.' || (prefix != ComponentJulian && prefix != ComponentTime)) {
+		return NewParseErrorf(t.idx+i, "unexpected character: %c", t.val[i])
+	}
+	switch prefix {
+	case ComponentTime:
+		// A concatenated time after T, e.g. T040506.
+		return s.decodeNumberField(t, s.seen|ComponentDateMask)
+	case ComponentJulian:
+		if err := s.markSeenUnique(t, ComponentDateMask); err != nil {
+			return err
+		}
+		if err := s.setJulianDay(t, num); err != nil {
+			return err
+		}
+		if frac != "" {
+			// The fractional part is the time of day.
+			if err := s.markSeenUnique(t, ComponentTimeMask); err != nil {
+				return err
+			}
+			f, err := strconv.ParseFloat(frac, 64)
+			if err != nil {
+				return NewParseErrorf(t.idx+i, "error parsing fraction: %s", err.Error())
+			}
+			micros := int64(f * float64(24*time.Hour/time.Microsecond))
+			s.hour = int(micros / int64(time.Hour/time.Microsecond))
+			s.minute = int(micros / int64(time.Minute/time.Microsecond) % 60)
+			s.second = int(micros / int64(time.Second/time.Microsecond) % 60)
+			s.nanos = int(micros%int64(time.Second/time.Microsecond)) * int(time.Microsecond)
+		}
+	default:
+		return NewParseErrorf(s.prefixToken.idx, "unexpected unit: %s", s.prefixToken.raw)
+	}
+	return nil
+}
+
+// setJulianDay sets the date from the given Julian day number.
+func (s *decodeTokenState) setJulianDay(t token, jd int) error {
+	if jd > math.MaxInt32 {
+		return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx, "julian day out of range: %s", t.val)
+	}
+	s.year, s.month, s.day = julianDayToDate(jd)
+	s.isJulian = true
+	return nil
+}
+
+// decodeJulianDayWithTimeZone decodes a Julian day with an attached time
+// zone, e.g. J2451187-08.
+func (s *decodeTokenState) decodeJulianDayWithTimeZone(t token) error {
+	s.prefix = 0
+	i := 0
+	num, err := s.readDigits(t, &i)
+	if err != nil {
+		return err
+	}
+	if err := s.markSeenUnique(t, ComponentDateMask|ComponentTimeMask); err != nil {
+		return err
+	}
+	if err := s.setJulianDay(t, num); err != nil {
+		return err
+	}
+	return s.decodeTimeZone(token{tokenType: tokenTypeTZ, val: t.val[i:], raw: t.raw[i:], idx: t.idx + i})
+}
+
 func (s *decodeTokenState) readDigits(t token, i *int) (int, error) {
 	start := *i
 	for *i < len(t.val) && unicode.IsDigit(rune(t.val[*i])) {
 		*i++
 	}
+	if start == *i {
+		return 0, NewParseError(t.idx+start, "expected digits but none found")
+	}
+	// As in PostgreSQL, fields must fit in 32 bits, which also prevents
+	// the year from overflowing when converted to a time.
 	ret, err := strconv.ParseInt(t.val[start:*i], 10, 64)
-	if err != nil {
-		return 0, NewParseErrorf(t.idx+*i+start, "error parsing digits: %s", err.Error())
+	if err != nil || ret > math.MaxInt32 {
+		return 0, NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, t.idx+start, "field value out of range: %s", t.val[start:*i])
 	}
 	return int(ret), nil
 }
 
 func (s *decodeTokenState) decodeTime(t token) error {
+	// The time may be preceded by T, as in ISO 8601.
+	s.prefix = 0
 	i := 0
 	if s.hasSeen(ComponentTimeMask) {
 		return NewParseErrorf(t.idx, "duplicate time Component: %s", t.val)
 	}
 	s.markSeen(ComponentTimeMask)
+	s.timeIdx = t.idx
 	var err error
 	// Read hour.
 	s.hour, err = s.readDigits(t, &i)
@@ -326,6 +716,9 @@ func (s *decodeTokenState) decodeTime(t token) error {
 	return NewParseErrorf(t.idx+i, "expected : or ., found %c", t.val[i])
 }
 
+// decodeFractionalSecond decodes a fraction of a second, e.g. .5, rounding
+// it to the nearest microsecond as PostgreSQL does. A fraction which rounds
+// up to a whole second is carried into the seconds when the time is built.
 func (s *decodeTokenState) decodeFractionalSecond(t token) error {
 	if len(t.val) == 0 {
 		return NewParseError(t.idx, "expected fractional second, found empty string")
@@ -333,49 +726,558 @@ func (s *decodeTokenState) decodeFractionalSecond(t token) error {
 	if t.val[0] != '.' {
 		return NewParseErrorf(t.idx, "expected ., found %c", t.val[0])
 	}
-	s.markSeen(ComponentMicros)
-	micros, err := strconv.ParseInt(t.val[1:], 10, 64)
+	if len(t.val) == 1 {
+		return NewParseError(t.idx+1, "expected digits but none found")
+	}
+	for i := 1; i < len(t.val); i++ {
+		if t.val[i] < '0' || t.val[i] > '9' {
+			return NewParseErrorf(t.idx+i, "unexpected character: %c", t.val[i])
+		}
+	}
+	frac, err := strconv.ParseFloat(t.val, 64)
 	if err != nil {
 		return NewParseErrorf(t.idx+1, "error parsing digits: %s", err.Error())
 	}
-	s.nanos = int(micros) * int(time.Microsecond)
+	s.markSeen(ComponentMicros)
+	s.nanos = int(math.RoundToEven(frac*1e6)) * int(time.Microsecond)
+	return nil
+}
+
+// setLocation sets the time zone the time is specified in.
+func (s *decodeTokenState) setLocation(t token, loc *time.Location) error {
+	if s.hasSeen(ComponentTZ) {
+		return NewParseErrorf(t.idx, "duplicate time zone: %s", t.val)
+	}
+	s.markSeen(ComponentTZ)
+	s.loc = loc
 	return nil
 }
 
-func decodeTokens(dateStyle DateStyle, now time.Time, tokens []token) (ParseResult, error) {
-	s := decodeTokenState{
-		typ:       ParseResultTypeAbsoluteTime,
-		dateStyle: dateStyle,
-		now:       now,
-		loc:       now.Location(),
+// setNamedLocation sets the time zone the time is specified in to a time
+// zone loaded by name, whose offset may depend on the date.
+func (s *decodeTokenState) setNamedLocation(t token, loc *time.Location) error {
+	if err := s.setLocation(t, loc); err != nil {
+		return err
 	}
+	s.namedZone = &t
+	return nil
+}
 
+// decodeTimeZone decodes a numeric time zone, e.g. +07 or -08:00.
+func (s *decodeTokenState) decodeTimeZone(t token) error {
+	offset, err := decodeTimeZoneOffset(t)
+	if err != nil {
+		return err
+	}
+	return s.setLocation(t, time.FixedZone("", offset))
+}
+
+// decodeTimeZoneAbbreviation decodes a time zone abbreviation, e.g. PST.
+func (s *decodeTokenState) decodeTimeZoneAbbreviation(t token, abbr TimeZoneAbbreviation) error {
+	if abbr.Zone == "" {
+		if abbr.Offset == 0 && (t.val == "utc" || t.val == "z" || t.val == "zulu") {
+			return s.setLocation(t, time.UTC)
+		}
+		return s.setLocation(t, time.FixedZone(strings.ToUpper(t.val), abbr.Offset))
+	}
+	loc, ok := s.zoneResolver.LoadLocation(abbr.Zone)
+	if !ok {
+		return NewParseErrorWithKindf(
+			ParseErrorKindUnknownTimeZone,
+			t.idx,
+			"time zone %s not recognized for time zone abbreviation %s",
+			abbr.Zone,
+			t.raw,
+		)
+	}
+	if err := s.setLocation(t, loc); err != nil {
+		return err
+	}
+	s.dynamicAbbreviation = &t
+	return nil
+}
+
+// resolveDynamicAbbreviation sets the time zone to the offset of the dynamic
+// time zone abbreviation at the decoded date and time, if one was given.
+func (s *decodeTokenState) resolveDynamicAbbreviation() {
+	if s.dynamicAbbreviation == nil {
+		return
+	}
+	abbr := strings.ToUpper(s.dynamicAbbreviation.val)
+	wallTime, _, _ := dateInLocation(s.year, time.Month(s.month), s.day, s.hour, s.minute, s.second, 0, s.loc)
+	s.loc = time.FixedZone(abbr, dynamicAbbreviationOffset(wallTime, abbr))
+	s.dynamicAbbreviation = nil
+}
+
+// decodeString decodes a string token.
+func (s *decodeTokenState) decodeString(t token) error {
+	// Time zone abbreviations take precedence over keywords.
+	if abbr, ok := s.zoneResolver.LookupAbbreviation(t.val); ok {
+		return s.decodeTimeZoneAbbreviation(t, abbr)
+	}
+	if kw, ok := keywords[t.val]; ok {
+		return s.decodeKeyword(t, kw)
+	}
+	if loc, ok := s.zoneResolver.LoadLocation(t.raw); ok {
+		return s.setNamedLocation(t, loc)
+	}
+	return NewParseErrorf(t.idx, "unknown string: %s", t.raw)
+}
+
+// decodeKeyword decodes a token which matched a keyword.
+func (s *decodeTokenState) decodeKeyword(t token, kw keyword) error {
+	switch kw.typ {
+	case ComponentNow:
+		if err := s.markSeenSpecial(t, ComponentDateMask|ComponentTimeMask|ComponentTZ); err != nil {
+			return err
+		}
+		s.typ = ParseResultTypeRelativeTime
+		s.loc = s.now.Location()
+		s.year, s.month, s.day = s.nowDate(0)
+		s.hour, s.minute, s.second = s.now.Clock()
+		s.nanos = s.now.Nanosecond()
+	case ComponentToday, ComponentTomorrow, ComponentYesterday:
+		if s.isTimeOnly() {
+			return NewParseErrorf(t.idx, "unexpected special value: %s", t.raw)
+		}
+		if err := s.markSeenSpecial(t, ComponentDateMask); err != nil {
+			return err
+		}
+		s.typ = ParseResultTypeRelativeTime
+		switch kw.typ {
+		case ComponentToday:
+			s.year, s.month, s.day = s.nowDate(0)
+		case ComponentTomorrow:
+			s.year, s.month, s.day = s.nowDate(1)
+		case ComponentYesterday:
+			s.year, s.month, s.day = s.nowDate(-1)
+		}
+	case ComponentZulu:
+		if err := s.markSeenSpecial(t, ComponentTimeMask|ComponentTZ); err != nil {
+			return err
+		}
+		s.hour, s.minute, s.second, s.nanos = 0, 0, 0, 0
+		s.loc = time.UTC
+	case ComponentEpoch, ComponentLate, ComponentEarly:
+		if s.isTimeOnly() {
+			return NewParseErrorf(t.idx, "unexpected special value: %s", t.raw)
+		}
+		if err := s.markSeenSpecial(t, ComponentDateMask|ComponentTimeMask|ComponentTZ); err != nil {
+			return err
+		}
+		s.special = kw.typ
+	case ComponentMonth:
+		if s.hasSeen(ComponentMonth) && !s.haveTextMonth && !s.hasSeen(ComponentDay) && s.month >= 1 && s.month <= 31 {
+			// We have already seen a numeric month, but no day, so treat
+			// that as the day instead, e.g. 12 Jan 2020.
+			s.day = s.month
+			s.dayIdx = s.monthIdx
+			s.markSeen(ComponentDay)
+		} else if s.hasSeen(ComponentMonth) {
+			return NewParseErrorf(t.idx, "duplicate month: %s", t.raw)
+		}
+		s.markSeen(ComponentMonth)
+		s.month = kw.val
+		s.monthIdx = t.idx
+		s.haveTextMonth = true
+	case ComponentAMPM:
+		if s.hasSeen(ComponentAMPM) {
+			return NewParseErrorf(t.idx, "duplicate AM/PM: %s", t.raw)
+		}
+		s.markSeen(ComponentAMPM)
+		s.meridian = kw.val
+		s.meridianIdx = t.idx
+	case ComponentADBC:
+		if s.hasSeen(ComponentADBC) {
+			return NewParseErrorf(t.idx, "duplicate era: %s", t.raw)
+		}
+		s.markSeen(ComponentADBC)
+		s.isBC = kw.val == eraBC
+	case ComponentJulian, ComponentTime:
+		if s.prefix != 0 {
+			return NewParseErrorf(t.idx, "unexpected unit: %s", t.raw)
+		}
+		// T must separate a date and a time, unless decoding a time of day.
+		if kw.typ == ComponentTime && !s.hasSeen(ComponentDateMask) && !s.isTimeOnly() {
+			return NewParseErrorf(t.idx, "expected date before %s", t.raw)
+		}
+		s.prefix = kw.typ
+		s.prefixToken = t
+	case ComponentString:
+		// Noise words are ignored.
+	case ComponentDOW:
+		// The day of the week is accepted but otherwise ignored.
+		if s.hasSeen(ComponentDOW) {
+			return NewParseErrorf(t.idx, "duplicate day of week: %s", t.raw)
+		}
+		s.markSeen(ComponentDOW)
+	default:
+		return NewParseErrorf(t.idx, "unexpected keyword: %s", t.val)
+	}
+	return nil
+}
+
+// markSeenSpecial marks the given Components as seen by a special value,
+// returning an error if any of them have already been seen.
+func (s *decodeTokenState) markSeenSpecial(t token, c Component) error {
+	if s.seen&c != 0 {
+		return NewParseErrorf(t.idx, "unexpected special value: %s", t.val)
+	}
+	s.markSeen(c)
+	return nil
+}
+
+// markSeenUnique marks the given Components as seen by a single token,
+// returning an error if any of them have already been seen.
+func (s *decodeTokenState) markSeenUnique(t token, c Component) error {
+	if s.seen&c != 0 {
+		return NewParseErrorf(t.idx, "conflicting value: %s", t.val)
+	}
+	s.markSeen(c)
+	return nil
+}
+
+// nowDate returns the date the given number of days after now.
+func (s *decodeTokenState) nowDate(days int) (year, month, day int) {
+	y, m, d := s.now.Date()
+	y, m, d = time.Date(y, m, d+days, 0, 0, 0, 0, time.UTC).Date()
+	return y, int(m), d
+}
+
+// adjustDate converts the year to the representation used by time.Time, in
+// which 1 BC is year 0, 2 BC is year -1 and so on, and resolves the day of
+// year into a month and day. Two-digit AD years are placed in the century
+// given by the two-digit year pivot.
+func (s *decodeTokenState) adjustDate() error {
+	if !s.hasSeen(ComponentYear) {
+		return nil
+	}
+	switch {
+	case s.isJulian:
+	case s.isBC:
+		// There is no year zero in AD/BC notation.
+		if s.year <= 0 {
+			return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, s.yearIdx, "year %d is out of range", s.year)
+		}
+		s.year = -(s.year - 1)
+	case s.is2DigitYear:
+		if s.opts.rejectTwoDigitYears {
+			return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, s.yearIdx, "two-digit year %02d is ambiguous", s.year)
+		}
+		if s.year < s.opts.twoDigitYearPivot {
+			s.year += 2000
+		} else {
+			s.year += 1900
+		}
+	case s.year <= 0:
+		return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, s.yearIdx, "year %d is out of range", s.year)
+	}
+	if s.hasSeen(ComponentDOY) {
+		s.year, s.month, s.day = julianDayToDate(dateToJulianDay(s.year, 1, 1) + s.dayOfYear - 1)
+	}
+	return s.validateDate()
+}
+
+// validateDate checks the month and day are valid. A month or day outside
+// of the possible range may mean the input is in a different order to the
+// DateStyle, which is reported separately to an invalid day of the month.
+func (s *decodeTokenState) validateDate() error {
+	if s.month < 1 || s.month > 12 {
+		return NewParseErrorWithKindf(ParseErrorKindMonthOrDayOutOfRange, s.monthIdx, "month %d is out of range", s.month)
+	}
+	if s.day < 1 || s.day > 31 {
+		return NewParseErrorWithKindf(ParseErrorKindMonthOrDayOutOfRange, s.dayIdx, "day %d is out of range", s.day)
+	}
+	if daysInMonth := time.Date(s.year, time.Month(s.month)+1, 0, 0, 0, 0, 0, time.UTC).Day(); s.day > daysInMonth {
+		return NewParseErrorWithKindf(
+			ParseErrorKindFieldOutOfRange,
+			s.dayIdx,
+			"day %d is out of range for %s",
+			s.day,
+			time.Month(s.month),
+		)
+	}
+	return nil
+}
+
+// time returns the decoded date and time in the decoded time zone, and the
+// offset used to read it. Wall clock times which do not exist or are
+// ambiguous due to a transition of the time zone are resolved as
+// PostgreSQL does, unless rejected by WithRejectDSTTransitionTimes.
+func (s *decodeTokenState) time() (time.Time, int, error) {
+	t, offset, transition := dateInLocation(
+		s.year,
+		time.Month(s.month),
+		s.day,
+		s.hour,
+		s.minute,
+		s.second,
+		s.nanos,
+		s.loc,
+	)
+	if s.opts.rejectDSTTransitionTimes {
+		switch transition {
+		case dstTransitionGap:
+			return time.Time{}, 0, NewParseErrorWithKindf(
+				ParseErrorKindFieldOutOfRange,
+				s.timeIdx,
+				"time %02d:%02d:%02d does not exist in time zone %s",
+				s.hour,
+				s.minute,
+				s.second,
+				s.loc,
+			)
+		case dstTransitionOverlap:
+			return time.Time{}, 0, NewParseErrorWithKindf(
+				ParseErrorKindFieldOutOfRange,
+				s.timeIdx,
+				"time %02d:%02d:%02d is ambiguous in time zone %s",
+				s.hour,
+				s.minute,
+				s.second,
+				s.loc,
+			)
+		}
+	}
+	return t, offset, nil
+}
+
+// validateTime checks the time is valid. As in PostgreSQL, 24:00:00 and leap
+// seconds (e.g. 23:59:60) are accepted.
+func (s *decodeTokenState) validateTime() error {
+	if !s.hasSeen(ComponentTimeMask) {
+		return nil
+	}
+	if s.hour < 0 || s.hour > 24 ||
+		s.minute < 0 || s.minute >= 60 ||
+		s.second < 0 || s.second > 60 ||
+		s.nanos < 0 || s.nanos > int(time.Second) {
+		return NewParseErrorWithKindf(
+			ParseErrorKindFieldOutOfRange,
+			s.timeIdx,
+			"time %02d:%02d:%02d is out of range",
+			s.hour,
+			s.minute,
+			s.second,
+		)
+	}
+	if d := time.Duration(s.hour)*time.Hour +
+		time.Duration(s.minute)*time.Minute +
+		time.Duration(s.second)*time.Second +
+		time.Duration(s.nanos); d > 24*time.Hour {
+		return NewParseErrorWithKindf(
+			ParseErrorKindFieldOutOfRange,
+			s.timeIdx,
+			"time %02d:%02d:%02d is out of range",
+			s.hour,
+			s.minute,
+			s.second,
+		)
+	}
+	return nil
+}
+
+// applyMeridian converts a time given in 12 hour format to 24 hour format.
+// As in PostgreSQL, 12 AM is midnight and 12 PM is noon.
+func (s *decodeTokenState) applyMeridian() error {
+	if s.meridian == 0 {
+		return nil
+	}
+	if s.hour > 12 {
+		return NewParseErrorWithKindf(ParseErrorKindFieldOutOfRange, s.meridianIdx, "hour %d is out of range for AM/PM", s.hour)
+	}
+	switch {
+	case s.meridian == meridianAM && s.hour == 12:
+		s.hour = 0
+	case s.meridian == meridianPM && s.hour != 12:
+		s.hour += 12
+	}
+	return nil
+}
+
+func newDecodeTokenState(
+	vt valueType, dateStyle DateStyle, now time.Time, opts parseOptions,
+) *decodeTokenState {
+	return &decodeTokenState{
+		valueType:      vt,
+		typ:            ParseResultTypeAbsoluteTime,
+		dateStyle:      dateStyle,
+		now:            now,
+		loc:            now.Location(),
+		opts:           opts,
+		zoneResolver:   opts.resolver(dateStyle.ZoneResolver),
+		leadingDateIdx: -1,
+	}
+}
+
+// decodeAll decodes each of the given tokens.
+func (s *decodeTokenState) decodeAll(tokens []token) error {
+	// A time of day may only be preceded by a date if it is followed by a
+	// time, or the input ends with a date (e.g. a time zone name).
+	if s.isTimeOnly() && len(tokens) >= 2 && tokens[0].tokenType == tokenTypeDate &&
+		(tokens[1].tokenType == tokenTypeTime || tokens[len(tokens)-1].tokenType == tokenTypeDate) {
+		s.leadingDateIdx = tokens[0].idx
+	}
 	for _, t := range tokens {
+		// Units must be followed by a value, or a time in the case of T.
+		if s.prefix != 0 && t.tokenType != tokenTypeNumber && t.tokenType != tokenTypeDate &&
+			!(s.prefix == ComponentTime && t.tokenType == tokenTypeTime) {
+			return NewParseErrorf(s.prefixToken.idx, "expected value after %s", s.prefixToken.raw)
+		}
 		switch t.tokenType {
 		case tokenTypeDate:
 			// Julian?
 			if err := s.decodeDate(t); err != nil {
-				return ParseResult{}, err
+				return err
 			}
 		case tokenTypeTime:
 			if err := s.decodeTime(t); err != nil {
-				return ParseResult{}, err
+				return err
+			}
+		case tokenTypeNumber:
+			if err := s.decodeNumberToken(t); err != nil {
+				return err
+			}
+		case tokenTypeTZ:
+			if err := s.decodeTimeZone(t); err != nil {
+				return err
+			}
+		case tokenTypeString:
+			if err := s.decodeString(t); err != nil {
+				return err
+			}
+		case tokenTypeSpecial:
+			kw, ok := keywords[removeSpaces(t.val)]
+			if !ok {
+				return NewParseErrorf(t.idx, "unknown special value: %s", t.val)
+			}
+			if err := s.decodeKeyword(t, kw); err != nil {
+				return err
 			}
 		default:
-			return ParseResult{}, NewParseErrorf(t.idx, "unknown token type %s", t.tokenType.String())
+			return NewParseErrorf(t.idx, "unknown token type %s", t.tokenType.String())
+		}
+	}
+	if s.prefix != 0 {
+		return NewParseErrorf(s.prefixToken.idx, "expected value after %s", s.prefixToken.raw)
+	}
+	return nil
+}
+
+func decodeTokens(
+	vt valueType, dateStyle DateStyle, now time.Time, tokens []token, opts parseOptions,
+) (ParseResult, error) {
+	s := newDecodeTokenState(vt, dateStyle, now, opts)
+	if err := s.decodeAll(tokens); err != nil {
+		return ParseResult{}, err
+	}
+	// Timestamps without time zone and dates are wall-clock values, so any
+	// time zone in the input is ignored.
+	if s.valueType == valueTypeTimestamp || s.valueType == valueTypeDate {
+		s.loc = time.UTC
+		s.dynamicAbbreviation = nil
+	}
+
+	switch s.special {
+	case ComponentEpoch:
+		return ParseResult{
+			Type: ParseResultTypeAbsoluteTime,
+			Time: time.Unix(0, 0).In(s.loc),
+		}, nil
+	case ComponentLate:
+		return ParseResult{Type: ParseResultTypePosInfinity}, nil
+	case ComponentEarly:
+		return ParseResult{Type: ParseResultTypeNegInfinity}, nil
+	}
+
+	if !s.hasSeen(ComponentDateMask) {
+		if s.seen&ComponentDateMask == 0 {
+			return ParseResult{}, NewParseError(0, "missing date")
 		}
+		return ParseResult{}, NewParseError(0, "incomplete date")
+	}
+	if err := s.adjustDate(); err != nil {
+		return ParseResult{}, err
+	}
+	if err := s.validateTime(); err != nil {
+		return ParseResult{}, err
+	}
+	if err := s.applyMeridian(); err != nil {
+		return ParseResult{}, err
+	}
+	if s.valueType == valueTypeDate {
+		// The time, if any, is discarded.
+		// The year is checked first so the Julian day cannot overflow.
+		if s.year < minTimestamp.Year() || s.year > maxDateYear {
+			return ParseResult{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "date out of range")
+		}
+		if jd := dateToJulianDay(s.year, s.month, s.day); jd < 0 || jd >= endDateJulianDay {
+			return ParseResult{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "date out of range")
+		}
+		return ParseResult{
+			Type: s.typ,
+			Time: time.Date(s.year, time.Month(s.month), s.day, 0, 0, 0, 0, s.loc),
+		}, nil
+	}
+	s.resolveDynamicAbbreviation()
+	t, _, err := s.time()
+	if err != nil {
+		return ParseResult{}, err
+	}
+	if t.Before(minTimestamp) || !t.Before(endTimestamp) {
+		return ParseResult{}, NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "timestamp out of range")
 	}
 	return ParseResult{
 		Type: s.typ,
-		Time: time.Date(
-			s.year,
-			time.Month(s.month),
-			s.day,
-			s.hour,
-			s.minute,
-			s.second,
-			s.nanos,
-			s.loc,
-		),
+		Time: t,
 	}, nil
 }
+
+// decodeTimeTokens decodes a time of day, returning the time as microseconds
+// since midnight and, for valueTypeTimeTZ, the offset of its time zone in
+// seconds east of UTC.
+func decodeTimeTokens(
+	vt valueType, dateStyle DateStyle, now time.Time, tokens []token, opts parseOptions,
+) (micros int64, offset int, err error) {
+	s := newDecodeTokenState(vt, dateStyle, now, opts)
+	if err := s.decodeAll(tokens); err != nil {
+		return 0, 0, err
+	}
+	if !s.hasSeen(ComponentTimeMask) {
+		return 0, 0, NewParseError(0, "missing time")
+	}
+	if s.seen&ComponentDateMask != 0 {
+		if !s.hasSeen(ComponentDateMask) {
+			return 0, 0, NewParseError(0, "incomplete date")
+		}
+		if err := s.adjustDate(); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err := s.validateTime(); err != nil {
+		return 0, 0, err
+	}
+	if err := s.applyMeridian(); err != nil {
+		return 0, 0, err
+	}
+	micros = ((int64(s.hour)*60+int64(s.minute))*60+int64(s.second))*int64(time.Second/time.Microsecond) +
+		int64(s.nanos/int(time.Microsecond))
+
+	// The offset of a time zone specified by name may depend on the date,
+	// so a date must also be specified unless its offset has never changed.
+	// Otherwise, the offset of the session time zone is determined using
+	// the current date if no date is specified.
+	if s.namedZone != nil && !s.hasSeen(ComponentDateMask) && !hasFixedOffset(s.loc) {
+		return 0, 0, NewParseErrorf(s.namedZone.idx, "time zone %s requires a date", s.namedZone.raw)
+	}
+	if !s.hasSeen(ComponentDateMask) {
+		s.year, s.month, s.day = s.nowDate(0)
+	}
+	s.resolveDynamicAbbreviation()
+	// As in PostgreSQL, the offset of a time which does not exist is the
+	// offset before the transition.
+	if _, offset, err = s.time(); err != nil {
+		return 0, 0, err
+	}
+	return micros, offset, nil
+}
@@ -51,8 +51,9 @@ func TestParse(t *testing.T) {
 	datadriven.RunTest(t, "testdata/parse", func(t *testing.T, d *datadriven.TestData) string {
 		now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
 		switch d.Cmd {
-		case "timestamptz":
+		case "timestamptz", "timestamp", "date", "time", "timetz":
 			dateStyle := DefaultDateStyle()
+			var opts []ParseOption
 			for _, arg := range d.CmdArgs {
 				switch strings.ToLower(arg.Key) {
 				case "datestyle":
@@ -61,16 +62,314 @@ func TestParse(t *testing.T) {
 						dateStyle, err = ParseDateStyle(val, dateStyle)
 						require.NoError(t, err)
 					}
+				case "pivot":
+					var pivot int
+					arg.Scan(t, 0, &pivot)
+					opts = append(opts, WithTwoDigitYearPivot(pivot))
+				case "abbrevs":
+					abbrevs, err := BuiltinTimeZoneAbbreviations(arg.Vals[0])
+					require.NoError(t, err)
+					opts = append(opts, WithTimeZoneAbbreviations(abbrevs))
 				default:
 					t.Fatalf("unknown key: %s", arg.Key)
 				}
 			}
-			r, err := ParseTimestampTZ(dateStyle, now, d.Input)
-			require.NoError(t, err)
-			return fmt.Sprintf("%s\n%s", r.Type.String(), Format(dateStyle, r.Time, true /* includeTimeZone */))
+			switch d.Cmd {
+			case "time":
+				r, err := ParseTime(dateStyle, now, d.Input, opts...)
+				require.NoError(t, err)
+				return fmt.Sprintf("%+v", r)
+			case "timetz":
+				r, err := ParseTimeTZ(dateStyle, now, d.Input, opts...)
+				require.NoError(t, err)
+				return fmt.Sprintf("%+v", r)
+			case "date":
+				r, err := ParseDate(dateStyle, now, d.Input, opts...)
+				require.NoError(t, err)
+				if r.Type == ParseResultTypePosInfinity || r.Type == ParseResultTypeNegInfinity {
+					return r.Type.String()
+				}
+				return fmt.Sprintf("%s\n%+v", r.Type.String(), r.Date)
+			}
+			parse, includeTimeZone := ParseTimestampTZ, true
+			if d.Cmd == "timestamp" {
+				parse, includeTimeZone = ParseTimestamp, false
+			}
+			r, err := parse(dateStyle, now, d.Input, opts...)
+			if err != nil {
+				return fmt.Sprintf("error: %s", err)
+			}
+			if r.Type == ParseResultTypePosInfinity || r.Type == ParseResultTypeNegInfinity {
+				return r.Type.String()
+			}
+			return fmt.Sprintf("%s\n%s", r.Type.String(), Format(dateStyle, r.Time, includeTimeZone))
 		default:
 			t.Fatalf("command unknown: %s", d.Cmd)
 		}
 		return ""
 	})
 }
+
+func TestParseTimestampTZError(t *testing.T) {
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"2020-09-02 15:16:17 +16", NewParseErrorWithKind(ParseErrorKindTimeZoneDisplacementOutOfRange, 20, "time zone displacement out of range: +16")},
+		{"2020-09-02 15:16:17 +08:60", NewParseErrorWithKind(ParseErrorKindTimeZoneDisplacementOutOfRange, 20, "time zone displacement out of range: +08:60")},
+		{"2020-09-02 15:16:17 +08 PST", NewParseError(24, "duplicate time zone: pst")},
+		{"2020-09-02 15:16:17 Mars/Olympus_Mons", NewParseErrorWithKind(ParseErrorKindUnknownTimeZone, 20, "time zone not recognized: Mars/Olympus_Mons")},
+		{"now 15:16:17", NewParseError(4, "duplicate time Component: 15:16:17")},
+		{"today yesterday", NewParseError(6, "unexpected special value: yesterday")},
+		{"2020-09-02 15:16:17 epoch", NewParseError(20, "unexpected special value: epoch")},
+		{"-tomorrow", NewParseError(0, "unknown special value: -tomorrow")},
+		{"2020-Foo-01", NewParseError(5, "unexpected text in date: Foo")},
+		{"Jan 12 Feb 2020", NewParseError(7, "duplicate month: Feb")},
+		{"Mon 12 Jan 2020 Tuesday", NewParseError(16, "duplicate day of week: Tuesday")},
+		{"12/16", NewParseError(0, "incomplete date: 12/16")},
+		{"2020-01-01 13:15 pm", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 17, "hour 13 is out of range for AM/PM")},
+		{"2020-01-01 11:15 am pm", NewParseError(20, "duplicate AM/PM: pm")},
+		{"0000-01-01", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "year 0 is out of range")},
+		{"0000-01-01 BC", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "year 0 is out of range")},
+		{"2020-01-01 BC AD", NewParseError(14, "duplicate era: AD")},
+		{"4714-11-23 23:59:59+00 BC", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "timestamp out of range")},
+		{"4714-11-24 00:30:00+01 BC", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "timestamp out of range")},
+		{"294277-01-01 00:00:00+00", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "timestamp out of range")},
+		{"J2451187 2020-01-01", NewParseError(13, "invalid time zone: -01-01")},
+		{"2020-01-01 J", NewParseError(11, "expected value after J")},
+		{"J 99999999999", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 2, "field value out of range: 99999999999")},
+		{"Jan 08 040506-08 1999", NewParseError(7, "conflicting value: 040506")},
+		{"19990108 04050", NewParseError(9, "unexpected number: 04050")},
+		{"T040506", NewParseError(0, "expected date before T")},
+		{"1999-01-08 T", NewParseError(11, "expected value after T")},
+		{"1999-01-08 T PST", NewParseError(11, "expected value after T")},
+		{"T15:16:17", NewParseError(0, "expected date before T")},
+		{"2020-09-02T15:16:17T", NewParseError(19, "expected value after T")},
+		{"J 15:16:17", NewParseError(0, "expected value after J")},
+		{"1999-01-08 T 19990108", NewParseError(13, "unexpected number: 19990108")},
+		{"2021-02-29", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 8, "day 29 is out of range for February")},
+		{"Feb 30 2020", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 4, "day 30 is out of range for February")},
+		{"2020-04-31", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 8, "day 31 is out of range for April")},
+		{"2020-13-01", NewParseErrorWithKind(ParseErrorKindMonthOrDayOutOfRange, 5, "month 13 is out of range")},
+		{"13/25/2020", NewParseErrorWithKind(ParseErrorKindMonthOrDayOutOfRange, 0, "month 13 is out of range")},
+		{"2020-01-32", NewParseErrorWithKind(ParseErrorKindMonthOrDayOutOfRange, 8, "day 32 is out of range")},
+		{"2020-00-10", NewParseErrorWithKind(ParseErrorKindMonthOrDayOutOfRange, 5, "month 0 is out of range")},
+		{"2020-01-01 15:60:00", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 15:60:00 is out of range")},
+		{"2020-01-01 25:00:00", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 25:00:00 is out of range")},
+		{"2020-01-01 15:16:61", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 15:16:61 is out of range")},
+		{"2020-01-01 24:00:01", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 24:00:01 is out of range")},
+		{"2020-01-01 246000", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 24:60:00 is out of range")},
+		{"15:16:17", NewParseError(0, "missing date")},
+		{"2020-09-02 .5", NewParseError(11, "unexpected fractional second: .5")},
+		{"2020-09-02 15:16:17.5 .5", NewParseError(22, "conflicting value: .5")},
+		{"2020-09-02 15:16:17.", NewParseError(20, "expected digits but none found")},
+		{"2020-12-31 24:00:00.5", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 24:00:00 is out of range")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseTimestampTZ(DefaultDateStyle(), now, tc.s)
+			require.Error(t, err)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+// TestParseFormatRoundTrip tests that timestamps with fractional seconds
+// re-parse to themselves when formatted in each DateStyle.
+func TestParseFormatRoundTrip(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	fixed := time.FixedZone("fixed offset", 5*60*60+30*60)
+	for _, tt := range []time.Time{
+		time.Date(2020, 9, 2, 15, 16, 17, 0, time.UTC),
+		time.Date(2020, 9, 2, 15, 16, 17, 500000000, time.UTC),
+		time.Date(2020, 9, 2, 15, 16, 17, 123456000, newYork),
+		time.Date(2020, 1, 2, 3, 4, 5, 1000, newYork),
+		time.Date(2020, 9, 2, 15, 16, 17, 250000000, fixed),
+		time.Date(-43, 3, 15, 12, 0, 0, 999999000, time.UTC),
+	} {
+		for _, style := range []Style{StyleISO, StyleSQL, StyleGerman, StylePostgres} {
+			for _, order := range []Order{OrderYMD, OrderDMY, OrderMDY} {
+				// German is always formatted as DMY, and SQL is formatted as MDY
+				// in YMD order, so neither can be parsed back in other orders.
+				if (style == StyleGerman && order != OrderDMY) || (style == StyleSQL && order == OrderYMD) {
+					continue
+				}
+				ds := DateStyle{Style: style, Order: order, FixedZonePrefix: "fixed offset"}
+				formatted := Format(ds, tt, true /* includeTimeZone */)
+				t.Run(fmt.Sprintf("%s/%s/%s", style, order, formatted), func(t *testing.T) {
+					r, err := ParseTimestampTZ(ds, tt, formatted)
+					require.NoError(t, err)
+					require.True(t, tt.Equal(r.Time), "expected %s, got %s", tt, r.Time)
+				})
+			}
+		}
+	}
+}
+
+func TestParseRejectTwoDigitYears(t *testing.T) {
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"07-09-02", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 6, "two-digit year 02 is ambiguous")},
+		{"Jan 08 99", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 7, "two-digit year 99 is ambiguous")},
+		{"990108", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "two-digit year 99 is ambiguous")},
+		{"2002-07-09", nil},
+		{"Jan 08 0099", nil},
+		{"Jan 08 99 BC", nil},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseTimestampTZ(DefaultDateStyle(), now, tc.s, WithRejectTwoDigitYears())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseRejectDSTTransitionTimes(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, ny)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"2021-03-14 02:30", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 02:30:00 does not exist in time zone America/New_York")},
+		{"2021-11-07 01:30", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 01:30:00 is ambiguous in time zone America/New_York")},
+		{"2021-03-14 02:30 Europe/London", nil},
+		{"2021-03-28 01:30 Europe/London", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 01:30:00 does not exist in time zone Europe/London")},
+		{"2021-03-14 01:59:59", nil},
+		{"2021-03-14 03:00", nil},
+		{"2021-11-07 00:59:59", nil},
+		{"2021-11-07 02:00", nil},
+		{"2021-03-14 02:30-05", nil},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseTimestampTZ(DefaultDateStyle(), now, tc.s, WithRejectDSTTransitionTimes())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, ny)
+	for _, tc := range []struct {
+		s        string
+		expected time.Time
+		err      error
+	}{
+		{s: "now", expected: time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)},
+		{s: "today", expected: time.Date(2020, 06, 26, 0, 0, 0, 0, time.UTC)},
+		{s: "2020-09-02 15:16:17", expected: time.Date(2020, 9, 2, 15, 16, 17, 0, time.UTC)},
+		{s: "2020-09-02 15:16:17-07", expected: time.Date(2020, 9, 2, 15, 16, 17, 0, time.UTC)},
+		{s: "4714-11-24 00:30:00+01 BC", expected: time.Date(-4713, 11, 24, 0, 30, 0, 0, time.UTC)},
+		{s: "4714-11-23 23:59:59 BC", err: NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "timestamp out of range")},
+		{s: "2020-09-02 15:16:17 Mars/Olympus_Mons", err: NewParseErrorWithKind(ParseErrorKindUnknownTimeZone, 20, "time zone not recognized: Mars/Olympus_Mons")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			r, err := ParseTimestamp(DefaultDateStyle(), now, tc.s)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, r.Time)
+		})
+	}
+}
+
+func TestParseDateError(t *testing.T) {
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"4714-11-23 BC", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "date out of range")},
+		{"5874898-01-01", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "date out of range")},
+		{"50505469855535080-01-01", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "field value out of range: 50505469855535080")},
+		{"2147483647-01-01", NewParseErrorWithKind(ParseErrorKindValueOutOfRange, 0, "date out of range")},
+		{"1999-02-29", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 8, "day 29 is out of range for February")},
+		{"1999-01-08 25:00:00", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 11, "time 25:00:00 is out of range")},
+		{"04:05:06", NewParseError(0, "missing date")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseDate(DefaultDateStyle(), now, tc.s)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseTimeError(t *testing.T) {
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+	for _, tc := range []struct {
+		s   string
+		err error
+	}{
+		{"04:05:06 America/New_York", NewParseError(9, "time zone America/New_York requires a date")},
+		{"24:00:00.5", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "time 24:00:00 is out of range")},
+		{"04:60", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 0, "time 04:60:00 is out of range")},
+		{"13:05 AM", NewParseErrorWithKind(ParseErrorKindFieldOutOfRange, 6, "hour 13 is out of range for AM/PM")},
+		{"04", NewParseError(0, "unexpected number: 04")},
+		{"today", NewParseError(0, "unexpected special value: today")},
+		{"epoch", NewParseError(0, "unexpected special value: epoch")},
+		{"infinity", NewParseError(0, "unexpected special value: infinity")},
+		{"PST", NewParseError(0, "missing time")},
+		{"1999-01 04:05:06", NewParseError(0, "incomplete date: 1999-01")},
+		{"Jan 04:05:06", NewParseError(0, "incomplete date")},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			_, err := ParseTime(DefaultDateStyle(), now, tc.s)
+			require.Equal(t, tc.err, err)
+			_, err = ParseTimeTZ(DefaultDateStyle(), now, tc.s)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestParseTimeTZSessionZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		now      time.Time
+		s        string
+		expected TimeTZ
+	}{
+		{
+			now:      time.Date(2020, 06, 26, 15, 16, 17, 0, ny),
+			s:        "04:05:06",
+			expected: TimeTZ{TimeOfDay: TimeOfDay{Hour: 4, Minute: 5, Second: 6}, Offset: -4 * 60 * 60},
+		},
+		{
+			now:      time.Date(2020, 01, 26, 15, 16, 17, 0, ny),
+			s:        "04:05:06",
+			expected: TimeTZ{TimeOfDay: TimeOfDay{Hour: 4, Minute: 5, Second: 6}, Offset: -5 * 60 * 60},
+		},
+		{
+			now:      time.Date(2020, 01, 26, 15, 16, 17, 0, ny),
+			s:        "2020-06-26 04:05:06",
+			expected: TimeTZ{TimeOfDay: TimeOfDay{Hour: 4, Minute: 5, Second: 6}, Offset: -4 * 60 * 60},
+		},
+		{
+			now:      time.Date(2020, 01, 26, 15, 16, 17, 0, ny),
+			s:        "now",
+			expected: TimeTZ{TimeOfDay: TimeOfDay{Hour: 15, Minute: 16, Second: 17}, Offset: -5 * 60 * 60},
+		},
+	} {
+		t.Run(fmt.Sprintf("%s/%s", tc.now, tc.s), func(t *testing.T) {
+			r, err := ParseTimeTZ(DefaultDateStyle(), tc.now, tc.s)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, r)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package pgdatetime
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -48,18 +50,74 @@ type DateStyle struct {
 	// timezone if it begins with this prefix.
 	// Leave blank to always output timezone name.
 	FixedZonePrefix string
+
+	// ZoneResolver is used to look up time zone names and abbreviations
+	// when parsing, and the zone abbreviation to output in the text styles.
+	// Leave nil to use DefaultZoneResolver.
+	ZoneResolver ZoneResolver
 }
 
 func (ds *DateStyle) String() string {
 	return fmt.Sprintf("DateStyle(%s,%s)", ds.Order, ds.Style)
 }
 
+// IntervalStyle refers to the output style of intervals supported by
+// PostgreSQL.
+// See also: https://www.postgresql.org/docs/current/datatype-datetime.html#DATATYPE-INTERVAL-OUTPUT
+type IntervalStyle uint8
+
+//go:generate stringer -type=IntervalStyle -trimprefix=IntervalStyle
+
+const (
+	IntervalStylePostgres IntervalStyle = iota
+	IntervalStylePostgresVerbose
+	IntervalStyleSQLStandard
+	IntervalStyleISO8601
+)
+
+// DefaultIntervalStyle returns the default IntervalStyle for Postgres.
+func DefaultIntervalStyle() IntervalStyle {
+	return IntervalStylePostgres
+}
+
 // ParseError is an error that appears during parsing.
 type ParseError struct {
 	Description string
 	Idx         int
+	Kind        ParseErrorKind
 }
 
+// ParseErrorKind classifies a ParseError, mirroring the errors PostgreSQL
+// returns for invalid datetime input.
+type ParseErrorKind int
+
+//go:generate stringer -type=ParseErrorKind -trimprefix=ParseErrorKind
+
+const (
+	// ParseErrorKindInvalidSyntax signifies the input is not in a recognized
+	// format, i.e. PostgreSQL's "invalid input syntax".
+	ParseErrorKindInvalidSyntax ParseErrorKind = iota
+	// ParseErrorKindFieldOutOfRange signifies a field has an impossible
+	// value, e.g. February 30th or minute 60, i.e. PostgreSQL's
+	// "date/time field value out of range".
+	ParseErrorKindFieldOutOfRange
+	// ParseErrorKindMonthOrDayOutOfRange signifies a month or day field is
+	// out of range, which may mean the input is in a different order to the
+	// DateStyle. PostgreSQL reports this as "date/time field value out of
+	// range" with a hint to change the DateStyle.
+	ParseErrorKindMonthOrDayOutOfRange
+	// ParseErrorKindTimeZoneDisplacementOutOfRange signifies a numeric time
+	// zone is out of range, i.e. PostgreSQL's "time zone displacement out of
+	// range".
+	ParseErrorKindTimeZoneDisplacementOutOfRange
+	// ParseErrorKindUnknownTimeZone signifies a time zone name was not
+	// recognized, i.e. PostgreSQL's "time zone not recognized".
+	ParseErrorKindUnknownTimeZone
+	// ParseErrorKindValueOutOfRange signifies the parsed value is outside
+	// the range supported by PostgreSQL, e.g. "timestamp out of range".
+	ParseErrorKindValueOutOfRange
+)
+
 // ParseResultType is the type of result time returns.
 type ParseResultType int
 
@@ -78,6 +136,8 @@ const (
 )
 
 // ParseResult returns the result of parsing a time.
+// Time is not set if Type is ParseResultTypePosInfinity or
+// ParseResultTypeNegInfinity.
 type ParseResult struct {
 	Type ParseResultType
 	Time time.Time
@@ -93,6 +153,20 @@ func NewParseErrorf(idx int, descriptionf string, args ...interface{}) *ParseErr
 	return &ParseError{Description: fmt.Sprintf(descriptionf, args...), Idx: idx}
 }
 
+// NewParseErrorWithKind returns a ParseError of the given kind with the given
+// fields.
+func NewParseErrorWithKind(kind ParseErrorKind, idx int, description string) *ParseError {
+	return &ParseError{Description: description, Idx: idx, Kind: kind}
+}
+
+// NewParseErrorWithKindf returns a ParseError of the given kind with the given
+// fields.
+func NewParseErrorWithKindf(
+	kind ParseErrorKind, idx int, descriptionf string, args ...interface{},
+) *ParseError {
+	return &ParseError{Description: fmt.Sprintf(descriptionf, args...), Idx: idx, Kind: kind}
+}
+
 // Error implements the error interface.
 func (pe *ParseError) Error() string {
 	return fmt.Sprintf(
@@ -105,28 +179,58 @@ func (pe *ParseError) Error() string {
 var _ error = (*ParseError)(nil)
 
 // ParseTimestampTZ parses a TimestampTZ element.
-func ParseTimestampTZ(dateStyle DateStyle, now time.Time, s string) (ParseResult, error) {
+func ParseTimestampTZ(
+	dateStyle DateStyle, now time.Time, s string, opts ...ParseOption,
+) (ParseResult, error) {
 	tokens, err := tokenizeDateTime(s)
 	if err != nil {
 		return ParseResult{}, err
 	}
-	return decodeTokens(dateStyle, now, tokens)
+	return decodeTokens(valueTypeTimestampTZ, dateStyle, now, tokens, makeParseOptions(opts))
+}
+
+// ParseTimestamp parses a Timestamp element, i.e. a timestamp without time
+// zone. Any time zone in the input is ignored, and the result is the
+// wall-clock time given in the input, in UTC.
+func ParseTimestamp(
+	dateStyle DateStyle, now time.Time, s string, opts ...ParseOption,
+) (ParseResult, error) {
+	tokens, err := tokenizeDateTime(s)
+	if err != nil {
+		return ParseResult{}, err
+	}
+	return decodeTokens(valueTypeTimestamp, dateStyle, now, tokens, makeParseOptions(opts))
 }
 
 func writeTimeToBuffer(buf *bytes.Buffer, t time.Time) {
 	buf.WriteString(t.Format(" 15:04:05.999999"))
 }
 
+// writeTextTimeZoneToBuffer writes the zone name of the given time. If the
+// zone name is omitted due to FixedZonePrefix, or the ZoneResolver has no
+// abbreviation for it, the numeric offset is written instead, which is
+// preceded by a space only in the Postgres style.
 func writeTextTimeZoneToBuffer(buf *bytes.Buffer, ds DateStyle, t time.Time) {
-	buf.WriteRune(' ')
-	z, _ := t.Zone()
-	// Only write zone name if it exists.
+	z, offset := t.Zone()
 	if ds.FixedZonePrefix == "" || !strings.HasPrefix(z, ds.FixedZonePrefix) {
-		buf.WriteString(t.Format("MST"))
+		r := ds.ZoneResolver
+		if r == nil {
+			r = DefaultZoneResolver()
+		}
+		if abbr, ok := r.ZoneAbbreviation(t); ok {
+			buf.WriteRune(' ')
+			buf.WriteString(abbr)
+			return
+		}
+	}
+	if ds.Style == StylePostgres {
+		buf.WriteRune(' ')
 	}
+	writeTimeZoneOffset(buf, offset)
 }
 
 // WriteToBuffer writes the given time into the given buffer.
+// This is a port of PostgreSQL's EncodeDateTime.
 func WriteToBuffer(buf *bytes.Buffer, ds DateStyle, t time.Time, includeTimeZone bool) {
 	// In years <= 0, should as BC.
 	isBC := false
@@ -140,17 +244,13 @@ func WriteToBuffer(buf *bytes.Buffer, ds DateStyle, t time.Time, includeTimeZone
 	}
 	switch ds.Style {
 	case StyleSQL:
-		switch ds.Order {
-		case OrderYMD:
-			outputYear()
-			buf.WriteString(t.Format("/01/02"))
-		case OrderDMY:
+		// As in PostgreSQL, YMD order is written as MDY.
+		if ds.Order == OrderDMY {
 			buf.WriteString(t.Format("02/01/"))
-			outputYear()
-		default:
+		} else {
 			buf.WriteString(t.Format("01/02/"))
-			outputYear()
 		}
+		outputYear()
 
 		writeTimeToBuffer(buf, t)
 		if includeTimeZone {
@@ -165,7 +265,11 @@ func WriteToBuffer(buf *bytes.Buffer, ds DateStyle, t time.Time, includeTimeZone
 			writeTextTimeZoneToBuffer(buf, ds, t)
 		}
 	case StylePostgres:
-		buf.WriteString(t.Format("Mon Jan 2 15:04:05.999999 "))
+		if ds.Order == OrderDMY {
+			buf.WriteString(t.Format("Mon 02 Jan 15:04:05.999999 "))
+		} else {
+			buf.WriteString(t.Format("Mon Jan 02 15:04:05.999999 "))
+		}
 		outputYear()
 		if includeTimeZone {
 			writeTextTimeZoneToBuffer(buf, ds, t)
@@ -178,16 +282,7 @@ func WriteToBuffer(buf *bytes.Buffer, ds DateStyle, t time.Time, includeTimeZone
 		writeTimeToBuffer(buf, t)
 		if includeTimeZone {
 			_, zoneOffset := t.Zone()
-			if minOffsetInSecs := zoneOffset % 3600; minOffsetInSecs == 0 {
-				buf.WriteString(t.Format("-07"))
-			} else {
-				// Only print the minute/second offset if it exists.
-				if secOffset := zoneOffset % 60; secOffset != 0 {
-					buf.WriteString(t.Format("-07:00:00"))
-				} else {
-					buf.WriteString(t.Format("-07:00"))
-				}
-			}
+			writeTimeZoneOffset(buf, zoneOffset)
 		}
 	}
 
@@ -230,3 +325,110 @@ func ParseDateStyle(s string, existingDateStyle DateStyle) (DateStyle, error) {
 	}
 	return ds, nil
 }
+
+// ParseIntervalStyle parses a given IntervalStyle.
+func ParseIntervalStyle(s string) (IntervalStyle, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "postgres":
+		return IntervalStylePostgres, nil
+	case "postgres_verbose":
+		return IntervalStylePostgresVerbose, nil
+	case "sql_standard":
+		return IntervalStyleSQLStandard, nil
+	case "iso_8601":
+		return IntervalStyleISO8601, nil
+	}
+	return 0, fmt.Errorf("unknown IntervalStyle parameter: %s", s)
+}
+
+// ParseTimeZoneSetting parses a value of the TimeZone setting, accepting
+// everything SET TIME ZONE does. LOCAL and DEFAULT return defaultLocation.
+// A number, e.g. -8 or 5.5, or an interval, e.g.
+// INTERVAL '+08:00' HOUR TO MINUTE, is an offset east of UTC. Otherwise, the
+// value is a time zone name, e.g. UTC or America/New_York, loaded using the
+// ZoneResolver given with WithZoneResolver, or a POSIX time zone
+// specification, e.g. PST8PDT or EST5EDT,M3.2.0,M11.1.0. As in PostgreSQL,
+// offsets in POSIX time zone specifications are west of UTC, so +05:30 is
+// five and a half hours behind UTC.
+// This is a port of PostgreSQL's check_timezone.
+func ParseTimeZoneSetting(
+	s string, defaultLocation *time.Location, opts ...ParseOption,
+) (*time.Location, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "local") || strings.EqualFold(s, "default") {
+		return defaultLocation, nil
+	}
+	if len(s) >= len("interval") && strings.EqualFold(s[:len("interval")], "interval") {
+		return parseIntervalTimeZone(s, opts)
+	}
+	if hours, err := strconv.ParseFloat(s, 64); err == nil {
+		// As in PostgreSQL, a number of hours is east of UTC, unlike the
+		// POSIX offsets below.
+		offset := math.Round(hours * 60 * 60)
+		if math.IsNaN(offset) || math.Abs(offset) > maxPOSIXTimeZoneOffset {
+			return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+		}
+		return fixedOffsetLocation(int(offset)), nil
+	}
+	if loc, ok := makeParseOptions(opts).resolver(nil).LoadLocation(s); ok {
+		return loc, nil
+	}
+	if loc, ok := loadPOSIXTimeZone(s); ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+}
+
+// timeZoneIntervalQualifiers maps the keywords of each SQL interval
+// qualifier, separated by single spaces, to the IntervalQualifier.
+var timeZoneIntervalQualifiers = map[string]IntervalQualifier{
+	"year":             IntervalQualifierYear,
+	"month":            IntervalQualifierMonth,
+	"day":              IntervalQualifierDay,
+	"hour":             IntervalQualifierHour,
+	"minute":           IntervalQualifierMinute,
+	"second":           IntervalQualifierSecond,
+	"year to month":    IntervalQualifierYearToMonth,
+	"day to hour":      IntervalQualifierDayToHour,
+	"day to minute":    IntervalQualifierDayToMinute,
+	"day to second":    IntervalQualifierDayToSecond,
+	"hour to minute":   IntervalQualifierHourToMinute,
+	"hour to second":   IntervalQualifierHourToSecond,
+	"minute to second": IntervalQualifierMinuteToSecond,
+}
+
+// parseIntervalTimeZone parses a TimeZone setting of the form
+// INTERVAL '+08:00' HOUR TO MINUTE, where the qualifier is optional.
+func parseIntervalTimeZone(s string, opts []ParseOption) (*time.Location, error) {
+	rest := strings.TrimSpace(s[len("interval"):])
+	if len(rest) == 0 || rest[0] != '\'' {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+	}
+	end := strings.IndexByte(rest[1:], '\'')
+	if end < 0 {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+	}
+	val, keywords := rest[1:end+1], strings.Fields(rest[end+2:])
+	if len(keywords) > 0 {
+		q, ok := timeZoneIntervalQualifiers[strings.ToLower(strings.Join(keywords, " "))]
+		if !ok {
+			return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+		}
+		opts = append(opts[:len(opts):len(opts)], WithIntervalQualifier(q))
+	}
+	iv, err := ParseInterval(val, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q: %v", s, err)
+	}
+	if iv.Months != 0 {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q: cannot specify months in time zone interval", s)
+	}
+	if iv.Days != 0 {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q: cannot specify days in time zone interval", s)
+	}
+	offset := iv.Micros / microsPerSecond
+	if offset > maxPOSIXTimeZoneOffset || offset < -maxPOSIXTimeZoneOffset {
+		return nil, fmt.Errorf("invalid value for parameter \"TimeZone\": %q", s)
+	}
+	return fixedOffsetLocation(int(offset)), nil
+}
@@ -75,6 +75,75 @@ func TestFormat(t *testing.T) {
 	})
 }
 
+// TestFormatDate tests formatting dates works, and when formatted, will
+// re-parse to itself correctly.
+func TestFormatDate(t *testing.T) {
+	datadriven.RunTest(t, "testdata/format_date", func(t *testing.T, d *datadriven.TestData) string {
+		now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+		switch d.Cmd {
+		case "date":
+			r, err := ParseDate(DefaultDateStyle(), now, d.Input)
+			require.NoError(t, err)
+
+			var ret strings.Builder
+			for _, style := range []Style{StyleISO, StyleSQL, StyleGerman, StylePostgres} {
+				for _, order := range []Order{OrderYMD, OrderDMY, OrderMDY} {
+					ds := DateStyle{Style: style, Order: order}
+					formatted := FormatDate(ds, r)
+					fmt.Fprintf(&ret, "%s/%s: %s\n", style, order, formatted)
+
+					// German is always formatted as DMY, so can only be parsed
+					// back in DMY order.
+					if style == StyleGerman && order != OrderDMY {
+						continue
+					}
+					// As in PostgreSQL, the SQL and Postgres styles are formatted
+					// as MDY in YMD order, which cannot be parsed back.
+					if (style == StyleSQL || style == StylePostgres) && order == OrderYMD {
+						continue
+					}
+					p, err := ParseDate(ds, now, formatted)
+					require.NoError(t, err, "parsing %s", formatted)
+					require.Equal(t, r, p, "parsing %s", formatted)
+				}
+			}
+			return ret.String()
+		default:
+			t.Fatalf("command unknown: %s", d.Cmd)
+		}
+		return ""
+	})
+}
+
+// TestFormatTime tests formatting times of day works, and when formatted,
+// will re-parse to itself correctly.
+func TestFormatTime(t *testing.T) {
+	datadriven.RunTest(t, "testdata/format_time", func(t *testing.T, d *datadriven.TestData) string {
+		now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+		switch d.Cmd {
+		case "time":
+			r, err := ParseTime(DefaultDateStyle(), now, d.Input)
+			require.NoError(t, err)
+			formatted := FormatTimeOfDay(r)
+			p, err := ParseTime(DefaultDateStyle(), now, formatted)
+			require.NoError(t, err, "parsing %s", formatted)
+			require.Equal(t, r, p, "parsing %s", formatted)
+			return formatted
+		case "timetz":
+			r, err := ParseTimeTZ(DefaultDateStyle(), now, d.Input)
+			require.NoError(t, err)
+			formatted := FormatTimeTZ(r)
+			p, err := ParseTimeTZ(DefaultDateStyle(), now, formatted)
+			require.NoError(t, err, "parsing %s", formatted)
+			require.Equal(t, r, p, "parsing %s", formatted)
+			return formatted
+		default:
+			t.Fatalf("command unknown: %s", d.Cmd)
+		}
+		return ""
+	})
+}
+
 func TestParseDateStyle(t *testing.T) {
 	for _, tc := range []struct {
 		initial  DateStyle
@@ -105,3 +174,116 @@ func TestParseDateStyle(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestParseIntervalStyle(t *testing.T) {
+	for _, tc := range []struct {
+		parse    string
+		expected IntervalStyle
+	}{
+		{"postgres", IntervalStylePostgres},
+		{"postgres_verbose", IntervalStylePostgresVerbose},
+		{"sql_standard", IntervalStyleSQLStandard},
+		{"iso_8601", IntervalStyleISO8601},
+		{"ISO_8601", IntervalStyleISO8601},
+	} {
+		t.Run(tc.parse, func(t *testing.T) {
+			p, err := ParseIntervalStyle(tc.parse)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := ParseIntervalStyle("bad")
+		require.Error(t, err)
+	})
+}
+
+func TestParseTimeZoneSetting(t *testing.T) {
+	winter := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2020, 7, 15, 12, 0, 0, 0, time.UTC)
+	defaultLocation := time.FixedZone("Default", 60*60)
+
+	type zone struct {
+		name   string
+		offset int
+	}
+	for _, tc := range []struct {
+		parse  string
+		winter zone
+		summer zone
+	}{
+		{"LOCAL", zone{"Default", 60 * 60}, zone{"Default", 60 * 60}},
+		{"default", zone{"Default", 60 * 60}, zone{"Default", 60 * 60}},
+		{"UTC", zone{"UTC", 0}, zone{"UTC", 0}},
+		{"utc", zone{"UTC", 0}, zone{"UTC", 0}},
+		{"Utc", zone{"UTC", 0}, zone{"UTC", 0}},
+		{"gmt", zone{"GMT", 0}, zone{"GMT", 0}},
+		{"america/port-au-prince", zone{"EST", -5 * 60 * 60}, zone{"EDT", -4 * 60 * 60}},
+		{"est5edt", zone{"EST", -5 * 60 * 60}, zone{"EDT", -4 * 60 * 60}},
+		{"etc/gmt+5", zone{"-05", -5 * 60 * 60}, zone{"-05", -5 * 60 * 60}},
+		{"America/New_York", zone{"EST", -5 * 60 * 60}, zone{"EDT", -4 * 60 * 60}},
+		{"europe/london", zone{"GMT", 0}, zone{"BST", 60 * 60}},
+		{"-8", zone{"-08", -8 * 60 * 60}, zone{"-08", -8 * 60 * 60}},
+		{"5.5", zone{"+05:30", 5*60*60 + 30*60}, zone{"+05:30", 5*60*60 + 30*60}},
+		{"0", zone{"+00", 0}, zone{"+00", 0}},
+		{"+05:30", zone{"-05:30", -5*60*60 - 30*60}, zone{"-05:30", -5*60*60 - 30*60}},
+		{"<+0330>-3:30", zone{"+0330", 3*60*60 + 30*60}, zone{"+0330", 3*60*60 + 30*60}},
+		{"FOO3", zone{"FOO", -3 * 60 * 60}, zone{"FOO", -3 * 60 * 60}},
+		{"ABC8XYZ", zone{"ABC", -8 * 60 * 60}, zone{"XYZ", -7 * 60 * 60}},
+		{"ABC5XYZ,M3.2.0,M11.1.0", zone{"ABC", -5 * 60 * 60}, zone{"XYZ", -4 * 60 * 60}},
+		{"AEST-10AEDT,M10.1.0,M4.1.0/3", zone{"AEDT", 11 * 60 * 60}, zone{"AEST", 10 * 60 * 60}},
+		{"ABC-1XYZ-3,J60/1,300", zone{"ABC", 60 * 60}, zone{"XYZ", 3 * 60 * 60}},
+		{"INTERVAL '+08:00' HOUR TO MINUTE", zone{"+08", 8 * 60 * 60}, zone{"+08", 8 * 60 * 60}},
+		{"interval '-05:30'", zone{"-05:30", -5*60*60 - 30*60}, zone{"-05:30", -5*60*60 - 30*60}},
+		{"INTERVAL '-3' HOUR", zone{"-03", -3 * 60 * 60}, zone{"-03", -3 * 60 * 60}},
+		{"interval '+05:30:15'  minute  to  second", zone{"+05:30:15", 5*60*60 + 30*60 + 15}, zone{"+05:30:15", 5*60*60 + 30*60 + 15}},
+	} {
+		t.Run(tc.parse, func(t *testing.T) {
+			loc, err := ParseTimeZoneSetting(tc.parse, defaultLocation)
+			require.NoError(t, err)
+			for _, expected := range []struct {
+				t    time.Time
+				zone zone
+			}{
+				{winter, tc.winter},
+				{summer, tc.summer},
+			} {
+				name, offset := expected.t.In(loc).Zone()
+				require.Equal(t, expected.zone, zone{name, offset}, "at %s", expected.t)
+			}
+		})
+	}
+
+	t.Run("zone resolver", func(t *testing.T) {
+		loc, err := ParseTimeZoneSetting("test/zone", defaultLocation, WithZoneResolver(fakeZoneResolver{}))
+		require.NoError(t, err)
+		require.Equal(t, fakeZone, loc)
+	})
+
+	for _, tc := range []struct {
+		parse string
+		err   string
+	}{
+		{"Mars/Olympus_Mons", `invalid value for parameter "TimeZone": "Mars/Olympus_Mons"`},
+		{"PST", `invalid value for parameter "TimeZone": "PST"`},
+		{"200", `invalid value for parameter "TimeZone": "200"`},
+		{"NaN", `invalid value for parameter "TimeZone": "NaN"`},
+		{"ABC5XYZ,M3.2.0", `invalid value for parameter "TimeZone": "ABC5XYZ,M3.2.0"`},
+		{"ABC5XYZ,M13.2.0,M11.1.0", `invalid value for parameter "TimeZone": "ABC5XYZ,M13.2.0,M11.1.0"`},
+		{"ABC5:60", `invalid value for parameter "TimeZone": "ABC5:60"`},
+		{"INTERVAL '1 day'", `invalid value for parameter "TimeZone": "INTERVAL '1 day'": cannot specify days in time zone interval`},
+		{"INTERVAL '1 month'", `invalid value for parameter "TimeZone": "INTERVAL '1 month'": cannot specify months in time zone interval`},
+		{"INTERVAL '+08:00' FORTNIGHT", `invalid value for parameter "TimeZone": "INTERVAL '+08:00' FORTNIGHT"`},
+		{"INTERVAL '+08:00", `invalid value for parameter "TimeZone": "INTERVAL '+08:00"`},
+		{"INTERVAL 'abc'", `invalid value for parameter "TimeZone": "INTERVAL 'abc'": error parsing datetime at index 0: unknown unit: abc`},
+		{"INTERVAL '+08:00' HOURTOMINUTE", `invalid value for parameter "TimeZone": "INTERVAL '+08:00' HOURTOMINUTE"`},
+		{"INTERVAL '+08:00' HOUR TOMINUTE", `invalid value for parameter "TimeZone": "INTERVAL '+08:00' HOUR TOMINUTE"`},
+		{"INTERVAL '+08:00' HOUR TO", `invalid value for parameter "TimeZone": "INTERVAL '+08:00' HOUR TO"`},
+	} {
+		t.Run(tc.parse, func(t *testing.T) {
+			_, err := ParseTimeZoneSetting(tc.parse, defaultLocation)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
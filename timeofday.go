@@ -0,0 +1,131 @@
+package pgdatetime
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a time of day with microsecond precision, as stored by the
+// PostgreSQL time type. As in PostgreSQL, 24:00:00 is a valid time of day.
+type TimeOfDay struct {
+	Hour, Minute, Second, Microsecond int
+}
+
+// NewTimeOfDay returns the TimeOfDay the given number of microseconds after
+// midnight.
+func NewTimeOfDay(micros int64) TimeOfDay {
+	const microsPerSecond = int64(time.Second / time.Microsecond)
+	return TimeOfDay{
+		Hour:        int(micros / (microsPerSecond * 60 * 60)),
+		Minute:      int(micros / (microsPerSecond * 60) % 60),
+		Second:      int(micros / microsPerSecond % 60),
+		Microsecond: int(micros % microsPerSecond),
+	}
+}
+
+// Duration returns the time elapsed since midnight.
+func (t TimeOfDay) Duration() time.Duration {
+	return time.Duration(t.Hour)*time.Hour +
+		time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second +
+		time.Duration(t.Microsecond)*time.Microsecond
+}
+
+// TimeTZ is a time of day with a time zone offset, as stored by the
+// PostgreSQL timetz type.
+type TimeTZ struct {
+	TimeOfDay
+	// Offset is the offset of the time zone in seconds east of UTC.
+	Offset int
+}
+
+// ParseTime parses a Time element, i.e. a time of day without time zone. A
+// date or time zone in the input is validated but otherwise discarded.
+func ParseTime(
+	dateStyle DateStyle, now time.Time, s string, opts ...ParseOption,
+) (TimeOfDay, error) {
+	tokens, err := tokenizeDateTime(s)
+	if err != nil {
+		return TimeOfDay{}, err
+	}
+	micros, _, err := decodeTimeTokens(valueTypeTime, dateStyle, now, tokens, makeParseOptions(opts))
+	if err != nil {
+		return TimeOfDay{}, err
+	}
+	return NewTimeOfDay(micros), nil
+}
+
+// ParseTimeTZ parses a TimeTZ element. If no time zone is specified, the
+// offset of the location of now on the current date is used. As the offset
+// of a time zone specified by name, e.g. America/New_York, depends on the
+// date, such time zones can only be used if a date is also specified,
+// unless their offset has never changed, e.g. Etc/GMT+5.
+func ParseTimeTZ(
+	dateStyle DateStyle, now time.Time, s string, opts ...ParseOption,
+) (TimeTZ, error) {
+	tokens, err := tokenizeDateTime(s)
+	if err != nil {
+		return TimeTZ{}, err
+	}
+	micros, offset, err := decodeTimeTokens(valueTypeTimeTZ, dateStyle, now, tokens, makeParseOptions(opts))
+	if err != nil {
+		return TimeTZ{}, err
+	}
+	return TimeTZ{TimeOfDay: NewTimeOfDay(micros), Offset: offset}, nil
+}
+
+// WriteTimeOfDayToBuffer writes the given time of day into the given
+// buffer. As in PostgreSQL, the output is the same in every DateStyle.
+// This is a port of PostgreSQL's EncodeTimeOnly.
+func WriteTimeOfDayToBuffer(buf *bytes.Buffer, t TimeOfDay) {
+	fmt.Fprintf(buf, "%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Microsecond != 0 {
+		// Trailing zeros in the fractional part are omitted.
+		buf.WriteString(strings.TrimRight(fmt.Sprintf(".%06d", t.Microsecond), "0"))
+	}
+}
+
+// FormatTimeOfDay formats the given time of day, e.g. 15:30:45.123456.
+func FormatTimeOfDay(t TimeOfDay) string {
+	var b bytes.Buffer
+	WriteTimeOfDayToBuffer(&b, t)
+	return b.String()
+}
+
+// WriteTimeTZToBuffer writes the given time of day with time zone into the
+// given buffer. As in PostgreSQL, the output is the same in every
+// DateStyle.
+func WriteTimeTZToBuffer(buf *bytes.Buffer, t TimeTZ) {
+	WriteTimeOfDayToBuffer(buf, t.TimeOfDay)
+	writeTimeZoneOffset(buf, t.Offset)
+}
+
+// FormatTimeTZ formats the given time of day with time zone, e.g.
+// 15:30:45.123456-08 or 15:30:45+05:30.
+func FormatTimeTZ(t TimeTZ) string {
+	var b bytes.Buffer
+	WriteTimeTZToBuffer(&b, t)
+	return b.String()
+}
+
+// writeTimeZoneOffset writes the given offset in seconds east of UTC,
+// only including the minutes and seconds if they are non-zero.
+// This is a port of PostgreSQL's EncodeTimezone.
+func writeTimeZoneOffset(buf *bytes.Buffer, offset int) {
+	sign := byte('+')
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	hour, min, sec := offset/3600, offset/60%60, offset%60
+	switch {
+	case sec != 0:
+		fmt.Fprintf(buf, "%c%02d:%02d:%02d", sign, hour, min, sec)
+	case min != 0:
+		fmt.Fprintf(buf, "%c%02d:%02d", sign, hour, min)
+	default:
+		fmt.Fprintf(buf, "%c%02d", sign, hour)
+	}
+}
@@ -0,0 +1,414 @@
+package pgdatetime
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTimeZoneOffsetHour is the largest hour offset from UTC that is accepted
+// for numeric time zones.
+const maxTimeZoneOffsetHour = 15
+
+// decodeTimeZoneOffset decodes a numeric time zone, e.g. +HH, +HHMM, +HH:MM
+// or +HH:MM:SS, returning the offset east of UTC in seconds.
+func decodeTimeZoneOffset(t token) (int, error) {
+	val := removeSpaces(t.val)
+	if len(val) < 2 || (val[0] != '+' && val[0] != '-') {
+		return 0, NewParseErrorf(t.idx, "invalid time zone: %s", t.val)
+	}
+	fields := strings.Split(val[1:], ":")
+	if len(fields) > 3 {
+		return 0, NewParseErrorf(t.idx, "invalid time zone: %s", t.val)
+	}
+	parts := make([]int, 3)
+	for i, field := range fields {
+		if field == "" {
+			return 0, NewParseErrorf(t.idx, "invalid time zone: %s", t.val)
+		}
+		for j := 0; j < len(field); j++ {
+			if field[j] < '0' || field[j] > '9' {
+				return 0, NewParseErrorf(t.idx, "invalid time zone: %s", t.val)
+			}
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, NewParseErrorWithKindf(ParseErrorKindTimeZoneDisplacementOutOfRange, t.idx, "time zone displacement out of range: %s", t.val)
+		}
+		parts[i] = v
+	}
+	// Run together hours and minutes, e.g. +0800.
+	if len(fields) == 1 && len(fields[0]) > 2 {
+		parts[0], parts[1] = parts[0]/100, parts[0]%100
+	}
+	hour, minute, second := parts[0], parts[1], parts[2]
+	if hour > maxTimeZoneOffsetHour || minute >= 60 || second >= 60 {
+		return 0, NewParseErrorWithKindf(ParseErrorKindTimeZoneDisplacementOutOfRange, t.idx, "time zone displacement out of range: %s", t.val)
+	}
+	offset := (hour*60+minute)*60 + second
+	if val[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// loadLocation loads the time zone with the given name. PostgreSQL time
+// zone names are case insensitive, so if the name cannot be found as given
+// it is looked up in an index of the names in the time zone database.
+func loadLocation(name string) (*time.Location, bool) {
+	// time.LoadLocation treats "" and "Local" specially, neither of
+	// which are valid time zone names.
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, false
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc, true
+	}
+	canonical, ok := zoneNameIndex()[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	if loc, err := time.LoadLocation(canonical); err == nil {
+		return loc, true
+	}
+	return nil, false
+}
+
+var zoneNames struct {
+	once  sync.Once
+	index map[string]string
+}
+
+// zoneNameIndex returns a map from the lower case name of each time zone in
+// the time zone database to its name, which is built on first use. The
+// sources time.LoadLocation reads from are searched in the same order, so
+// the name found is the one it loads. Time zones embedded with the
+// time/tzdata package are not indexed, so can only be loaded as named.
+func zoneNameIndex() map[string]string {
+	zoneNames.once.Do(func() {
+		zoneNames.index = make(map[string]string)
+		add := func(name string) {
+			if _, ok := zoneNames.index[strings.ToLower(name)]; !ok {
+				zoneNames.index[strings.ToLower(name)] = name
+			}
+		}
+		sources := []string{
+			"/usr/share/zoneinfo/",
+			"/usr/share/lib/zoneinfo/",
+			"/usr/lib/locale/TZ/",
+			filepath.Join(runtime.GOROOT(), "lib", "time", "zoneinfo.zip"),
+		}
+		if z := os.Getenv("ZONEINFO"); z != "" {
+			sources = append([]string{z}, sources...)
+		}
+		for _, source := range sources {
+			if strings.HasSuffix(source, ".zip") {
+				if r, err := zip.OpenReader(source); err == nil {
+					for _, f := range r.File {
+						add(f.Name)
+					}
+					_ = r.Close()
+				}
+				continue
+			}
+			_ = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return nil
+				}
+				if name, err := filepath.Rel(source, path); err == nil {
+					add(filepath.ToSlash(name))
+				}
+				return nil
+			})
+		}
+	})
+	return zoneNames.index
+}
+
+// hasFixedOffset returns whether the offset of the given time zone has never
+// changed, e.g. UTC or Etc/GMT+5, as PostgreSQL's pg_get_timezone_offset
+// does. The time package does not expose the transitions of a time zone,
+// so the zone in effect before the first transition is compared with the
+// zones in effect in the winter and summer after the last transition.
+// These always differ for time zones with transitions in practice, as the
+// first zone is local mean time.
+func hasFixedOffset(loc *time.Location) bool {
+	name, offset := time.Unix(math.MinInt64/2, 0).In(loc).Zone()
+	for _, month := range []time.Month{time.January, time.July} {
+		if n, o := time.Date(2200, month, 1, 0, 0, 0, 0, loc).Zone(); n != name || o != offset {
+			return false
+		}
+	}
+	return true
+}
+
+// dstTransition classifies a wall clock time near a change in the offset of
+// its time zone, e.g. due to daylight saving time.
+type dstTransition int
+
+const (
+	// dstTransitionNone signifies the wall clock time occurs exactly once.
+	dstTransitionNone dstTransition = iota
+	// dstTransitionGap signifies the wall clock time does not exist, as the
+	// clocks were put forward past it.
+	dstTransitionGap
+	// dstTransitionOverlap signifies the wall clock time occurs twice, as
+	// the clocks were put back over it.
+	dstTransitionOverlap
+)
+
+// dateInLocation returns the time with the given wall clock time in loc.
+// Unlike time.Date, a wall clock time skipped by a transition is read
+// using the offset before the transition, e.g. 02:30 on the day clocks are
+// put forward from 02:00 EST to 03:00 EDT is 03:30 EDT, and a wall clock
+// time which occurs twice is read using the offset after the transition,
+// e.g. 01:30 on the day clocks are put back from 02:00 EDT to 01:00 EST is
+// 01:30 EST. The offset used to read the wall clock time is also returned,
+// which differs from the offset of the returned time if it does not exist.
+// This is a port of PostgreSQL's DetermineTimeZoneOffset, which assumes
+// transitions are at least 48 hours apart.
+func dateInLocation(
+	year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location,
+) (time.Time, int, dstTransition) {
+	// wallTime is the wall clock time as if it were in UTC.
+	wallTime := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+	before := wallTime.Add(-24 * time.Hour)
+	after := wallTime.Add(24 * time.Hour)
+	_, beforeOffset := before.In(loc).Zone()
+	_, afterOffset := after.In(loc).Zone()
+	beforeTime := wallTime.Add(-time.Duration(beforeOffset) * time.Second)
+	if beforeOffset == afterOffset {
+		return beforeTime.In(loc), beforeOffset, dstTransitionNone
+	}
+	afterTime := wallTime.Add(-time.Duration(afterOffset) * time.Second)
+
+	// Find the transition, i.e. the first second with the new offset.
+	lo, hi := before.Unix(), after.Unix()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if _, offset := time.Unix(mid, 0).In(loc).Zone(); offset == beforeOffset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	transition := time.Unix(hi, 0)
+
+	switch {
+	case beforeTime.Before(transition) && afterTime.Before(transition):
+		return beforeTime.In(loc), beforeOffset, dstTransitionNone
+	case !beforeTime.Before(transition) && !afterTime.Before(transition):
+		return afterTime.In(loc), afterOffset, dstTransitionNone
+	case beforeTime.After(afterTime):
+		return beforeTime.In(loc), beforeOffset, dstTransitionGap
+	default:
+		return afterTime.In(loc), afterOffset, dstTransitionOverlap
+	}
+}
+
+// maxPOSIXTimeZoneOffset is the largest offset from UTC in seconds accepted
+// in POSIX time zone specifications, i.e. just under a week.
+const maxPOSIXTimeZoneOffset = (7*24 - 1) * 60 * 60
+
+// fixedOffsetLocation returns a time zone with the given offset east of UTC
+// in seconds, abbreviated as the offset, e.g. +05:30.
+// This is a port of PostgreSQL's pg_tzset_offset.
+func fixedOffsetLocation(offset int) *time.Location {
+	var buf bytes.Buffer
+	writeTimeZoneOffset(&buf, offset)
+	return time.FixedZone(buf.String(), offset)
+}
+
+// loadPOSIXTimeZone loads a POSIX time zone specification, e.g. PST8PDT or
+// EST5EDT,M3.2.0,M11.1.0, i.e. std offset [dst [offset] [,rule,rule]].
+// Offsets are west of UTC. As in PostgreSQL, the standard time abbreviation
+// may be empty, e.g. +05:30, in which case the time zone is abbreviated as
+// its offset east of UTC, and if daylight saving time is given without
+// rules, the rules of the United States are used.
+func loadPOSIXTimeZone(s string) (*time.Location, bool) {
+	std, rest, ok := splitPOSIXTimeZoneName(s)
+	if !ok || rest == "" {
+		return nil, false
+	}
+	stdOffset, rest, ok := splitPOSIXTimeZoneOffset(rest)
+	if !ok {
+		return nil, false
+	}
+	if std == "" {
+		var buf bytes.Buffer
+		writeTimeZoneOffset(&buf, -stdOffset)
+		std = buf.String()
+	}
+	// The specification is rewritten with quoted abbreviations, as the time
+	// package requires unquoted abbreviations to be at least three letters.
+	spec := "<" + std + ">" + formatPOSIXTimeZoneOffset(stdOffset)
+	if rest != "" {
+		dst, dstRest, ok := splitPOSIXTimeZoneName(rest)
+		if !ok || dst == "" {
+			return nil, false
+		}
+		spec += "<" + dst + ">"
+		rest = dstRest
+		if rest != "" && rest[0] != ',' {
+			dstOffset, dstRest, ok := splitPOSIXTimeZoneOffset(rest)
+			if !ok {
+				return nil, false
+			}
+			spec += formatPOSIXTimeZoneOffset(dstOffset)
+			rest = dstRest
+		}
+		if rest != "" {
+			rules := strings.Split(rest[1:], ",")
+			if rest[0] != ',' || len(rules) != 2 {
+				return nil, false
+			}
+			for _, rule := range rules {
+				if !validPOSIXTimeZoneRule(rule) {
+					return nil, false
+				}
+			}
+			spec += rest
+		}
+	}
+	loc, err := time.LoadLocationFromTZData(s, makeTZif(std, -stdOffset, spec))
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// splitPOSIXTimeZoneName splits the abbreviation from the start of a POSIX
+// time zone specification, which is either quoted in angle brackets, e.g.
+// <+0530>, or runs up to the following offset or rule.
+func splitPOSIXTimeZoneName(s string) (string, string, bool) {
+	if strings.HasPrefix(s, "<") {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return "", "", false
+		}
+		return s[1:end], s[end+1:], true
+	}
+	end := strings.IndexAny(s, "0123456789,+-")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:], true
+}
+
+// splitPOSIXTimeZoneOffset splits the offset from the start of a POSIX time
+// zone specification, i.e. [+-]hh[:mm[:ss]], returning it in seconds.
+func splitPOSIXTimeZoneOffset(s string) (int, string, bool) {
+	negative := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	var parts [3]int
+	for i := range parts {
+		if i > 0 {
+			if s == "" || s[0] != ':' {
+				break
+			}
+			s = s[1:]
+		}
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		if n == 0 || n > 3 {
+			return 0, "", false
+		}
+		parts[i], _ = strconv.Atoi(s[:n])
+		s = s[n:]
+	}
+	offset := (parts[0]*60+parts[1])*60 + parts[2]
+	if parts[1] >= 60 || parts[2] >= 60 || offset > maxPOSIXTimeZoneOffset {
+		return 0, "", false
+	}
+	if negative {
+		offset = -offset
+	}
+	return offset, s, true
+}
+
+// formatPOSIXTimeZoneOffset formats an offset in seconds as in a POSIX time
+// zone specification, i.e. [-]hh[:mm[:ss]].
+func formatPOSIXTimeZoneOffset(offset int) string {
+	sign := ""
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	hour, minute, second := offset/(60*60), offset/60%60, offset%60
+	switch {
+	case second != 0:
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, hour, minute, second)
+	case minute != 0:
+		return fmt.Sprintf("%s%d:%02d", sign, hour, minute)
+	default:
+		return fmt.Sprintf("%s%d", sign, hour)
+	}
+}
+
+// validPOSIXTimeZoneRule returns whether the given rule for the start or end
+// of daylight saving time is valid, i.e. Jn, n or Mm.w.d, optionally
+// followed by /time.
+func validPOSIXTimeZoneRule(rule string) bool {
+	date, at := rule, ""
+	if i := strings.IndexByte(rule, '/'); i >= 0 {
+		date, at = rule[:i], rule[i+1:]
+		if _, rest, ok := splitPOSIXTimeZoneOffset(at); !ok || rest != "" {
+			return false
+		}
+	}
+	inRange := func(s string, min, max int) bool {
+		v, err := strconv.Atoi(s)
+		return err == nil && s[0] >= '0' && s[0] <= '9' && v >= min && v <= max
+	}
+	switch {
+	case strings.HasPrefix(date, "J"):
+		return inRange(date[1:], 1, 365)
+	case strings.HasPrefix(date, "M"):
+		fields := strings.Split(date[1:], ".")
+		return len(fields) == 3 &&
+			inRange(fields[0], 1, 12) && inRange(fields[1], 1, 5) && inRange(fields[2], 0, 6)
+	default:
+		return inRange(date, 0, 365)
+	}
+}
+
+// makeTZif returns time zone data in the TZif format read by
+// time.LoadLocationFromTZData, describing a time zone with no transitions
+// which follows the given POSIX time zone specification. The abbreviation
+// and offset east of UTC of standard time are also given, which are used
+// before the specification takes effect.
+func makeTZif(abbr string, offset int, spec string) []byte {
+	var buf bytes.Buffer
+	// The version 1 data is followed by the version 2 data, which is the
+	// same as there are no transitions.
+	for i := 0; i < 2; i++ {
+		buf.WriteString("TZif2")
+		buf.Write(make([]byte, 15))
+		// The counts of UT/local indicators, standard/wall indicators, leap
+		// seconds, transitions, local time types and abbreviation bytes.
+		for _, count := range []int{0, 0, 0, 0, 1, len(abbr) + 1} {
+			_ = binary.Write(&buf, binary.BigEndian, uint32(count))
+		}
+		_ = binary.Write(&buf, binary.BigEndian, int32(offset))
+		// The DST indicator and abbreviation index.
+		buf.Write([]byte{0, 0})
+		buf.WriteString(abbr)
+		buf.WriteByte(0)
+	}
+	buf.WriteString("\n" + spec + "\n")
+	return buf.Bytes()
+}
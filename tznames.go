@@ -0,0 +1,229 @@
+package pgdatetime
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxTimeZoneAbbreviationLength is the maximum length of a time zone
+	// abbreviation, i.e. PostgreSQL's TOKMAXLEN.
+	maxTimeZoneAbbreviationLength = 10
+	// maxTimeZoneAbbreviationOffset is the largest offset from UTC in
+	// seconds accepted for a time zone abbreviation.
+	maxTimeZoneAbbreviationOffset = 14 * 60 * 60
+	// maxTimeZoneFileDepth is the maximum depth of @INCLUDE directives.
+	maxTimeZoneFileDepth = 3
+)
+
+//go:embed tznames
+var tznamesFS embed.FS
+
+// builtinTimeZoneAbbreviations contains the sets of time zone abbreviations
+// shipped with PostgreSQL.
+var builtinTimeZoneAbbreviations = func() fs.FS {
+	fsys, err := fs.Sub(tznamesFS, "tznames")
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}()
+
+var defaultTimeZoneAbbreviations = func() *TimeZoneAbbreviations {
+	a, err := BuiltinTimeZoneAbbreviations("Default")
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// TimeZoneAbbreviation is the definition of a time zone abbreviation, e.g.
+// PST or CEST.
+type TimeZoneAbbreviation struct {
+	// Offset is the offset of the time zone in seconds east of UTC.
+	Offset int
+	// IsDST is set if the abbreviation denotes daylight saving time.
+	IsDST bool
+	// Zone is set to the name of a time zone, e.g. Europe/Moscow, if the
+	// abbreviation is dynamic, i.e. its offset depends on the date and is
+	// determined by the abbreviations used by the time zone. Offset and
+	// IsDST are not set for dynamic abbreviations.
+	Zone string
+}
+
+// TimeZoneAbbreviations is a set of time zone abbreviations, as configured
+// by PostgreSQL's timezone_abbreviations setting.
+type TimeZoneAbbreviations struct {
+	entries map[string]timeZoneAbbreviationEntry
+}
+
+// timeZoneAbbreviationEntry is a TimeZoneAbbreviation with the location it
+// was defined at, for reporting conflicting definitions.
+type timeZoneAbbreviationEntry struct {
+	TimeZoneAbbreviation
+	filename string
+	line     int
+}
+
+// DefaultTimeZoneAbbreviations returns the Default set of time zone
+// abbreviations, which is used when parsing unless another set is given
+// with WithTimeZoneAbbreviations.
+func DefaultTimeZoneAbbreviations() *TimeZoneAbbreviations {
+	return defaultTimeZoneAbbreviations
+}
+
+// BuiltinTimeZoneAbbreviations returns the set of time zone abbreviations
+// with the given name shipped with PostgreSQL, i.e. Default, Australia or
+// India.
+func BuiltinTimeZoneAbbreviations(name string) (*TimeZoneAbbreviations, error) {
+	return LoadTimeZoneAbbreviations(builtinTimeZoneAbbreviations, name)
+}
+
+// LoadTimeZoneAbbreviations loads the set of time zone abbreviations from
+// the file with the given name in fsys, in the format of PostgreSQL's
+// share/timezonesets files. Files named by @INCLUDE directives are also
+// loaded from fsys.
+func LoadTimeZoneAbbreviations(fsys fs.FS, name string) (*TimeZoneAbbreviations, error) {
+	a := &TimeZoneAbbreviations{entries: make(map[string]timeZoneAbbreviationEntry)}
+	if err := a.loadFile(fsys, name, 0); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Lookup returns the definition of the given time zone abbreviation.
+// Abbreviations are case insensitive.
+func (a *TimeZoneAbbreviations) Lookup(abbr string) (TimeZoneAbbreviation, bool) {
+	e, ok := a.entries[strings.ToLower(abbr)]
+	return e.TimeZoneAbbreviation, ok
+}
+
+// loadFile adds the abbreviations in the given file to the set.
+// This is a port of PostgreSQL's ParseTzFile.
+func (a *TimeZoneAbbreviations) loadFile(fsys fs.FS, filename string, depth int) error {
+	// Only allow letters in file names, as PostgreSQL does.
+	if filename == "" || strings.Trim(filename, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("invalid time zone file name %q", filename)
+	}
+	if depth > maxTimeZoneFileDepth {
+		return fmt.Errorf("time zone file recursion limit exceeded in file %q", filename)
+	}
+	contents, err := fs.ReadFile(fsys, filename)
+	if err != nil {
+		return fmt.Errorf("could not read time zone file %q: %w", filename, err)
+	}
+	override := false
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case strings.EqualFold(fields[0], "@INCLUDE"):
+			if len(fields) < 2 || fields[1][0] == '#' {
+				return fmt.Errorf("@INCLUDE without file name in time zone file %q, line %d", filename, lineNum)
+			}
+			if err := a.loadFile(fsys, fields[1], depth+1); err != nil {
+				return err
+			}
+			continue
+		case strings.EqualFold(fields[0], "@OVERRIDE"):
+			override = true
+			continue
+		}
+		e, err := parseTimeZoneAbbreviationLine(fields, filename, lineNum)
+		if err != nil {
+			return err
+		}
+		abbr := strings.ToLower(fields[0])
+		if existing, ok := a.entries[abbr]; ok && existing.TimeZoneAbbreviation != e.TimeZoneAbbreviation && !override {
+			return fmt.Errorf(
+				"time zone abbreviation %q is multiply defined: entry in time zone file %q, line %d, conflicts with entry in file %q, line %d",
+				fields[0], filename, lineNum, existing.filename, existing.line,
+			)
+		}
+		a.entries[abbr] = e
+	}
+	return scanner.Err()
+}
+
+// parseTimeZoneAbbreviationLine parses the fields of a line defining a time
+// zone abbreviation, i.e. <abbreviation> <offset> [D] or
+// <abbreviation> <time zone name>.
+func parseTimeZoneAbbreviationLine(
+	fields []string, filename string, lineNum int,
+) (timeZoneAbbreviationEntry, error) {
+	e := timeZoneAbbreviationEntry{filename: filename, line: lineNum}
+	abbr := fields[0]
+	if len(abbr) > maxTimeZoneAbbreviationLength {
+		return e, fmt.Errorf(
+			"time zone abbreviation %q is too long (maximum %d characters) in time zone file %q, line %d",
+			abbr, maxTimeZoneAbbreviationLength, filename, lineNum,
+		)
+	}
+	if len(fields) < 2 || fields[1][0] == '#' {
+		return e, fmt.Errorf("missing time zone offset in time zone file %q, line %d", filename, lineNum)
+	}
+	rest := fields[2:]
+	// As in PostgreSQL, time zone names are assumed not to begin with a
+	// digit or sign. The time zone is not loaded until it is used.
+	if c := fields[1][0]; (c < '0' || c > '9') && c != '+' && c != '-' {
+		e.Zone = fields[1]
+	} else {
+		offset, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return e, fmt.Errorf("invalid number for time zone offset in time zone file %q, line %d", filename, lineNum)
+		}
+		if offset > maxTimeZoneAbbreviationOffset || offset < -maxTimeZoneAbbreviationOffset {
+			return e, fmt.Errorf("time zone offset %d is out of range in time zone file %q, line %d", offset, filename, lineNum)
+		}
+		e.Offset = offset
+		if len(rest) > 0 && strings.EqualFold(rest[0], "D") {
+			e.IsDST = true
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 && rest[0][0] != '#' {
+		return e, fmt.Errorf("invalid syntax in time zone file %q, line %d", filename, lineNum)
+	}
+	return e, nil
+}
+
+// dynamicAbbreviationSearchStep is the interval at which the abbreviations
+// used by a time zone are sampled when resolving a dynamic abbreviation.
+// Abbreviations used for shorter periods may not be found.
+const dynamicAbbreviationSearchStep = 7 * 24 * time.Hour
+
+// dynamicAbbreviationSearchLimit is the furthest the abbreviations used by a
+// time zone are sampled from the time being resolved.
+const dynamicAbbreviationSearchLimit = 200 * 365 * 24 * time.Hour
+
+// dynamicAbbreviationOffset returns the offset of the given abbreviation in
+// the time zone of t. If the abbreviation is in use at t, its offset at t is
+// returned. Otherwise, the offset of the abbreviation the last time it was
+// used before t is returned, or failing that the next time it is used after
+// t. If the time zone never uses the abbreviation, the offset of the time
+// zone at t is returned.
+// This is a port of PostgreSQL's DetermineTimeZoneAbbrevOffset.
+func dynamicAbbreviationOffset(t time.Time, abbr string) int {
+	name, offset := t.Zone()
+	if strings.EqualFold(name, abbr) {
+		return offset
+	}
+	for _, step := range []time.Duration{-dynamicAbbreviationSearchStep, dynamicAbbreviationSearchStep} {
+		for i := time.Duration(1); i*dynamicAbbreviationSearchStep <= dynamicAbbreviationSearchLimit; i++ {
+			if name, abbrOffset := t.Add(i * step).Zone(); strings.EqualFold(name, abbr) {
+				return abbrOffset
+			}
+		}
+	}
+	return offset
+}
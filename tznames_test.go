@@ -0,0 +1,119 @@
+package pgdatetime
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTimeZoneAbbreviations(t *testing.T) {
+	for _, tc := range []struct {
+		set      string
+		abbr     string
+		expected TimeZoneAbbreviation
+	}{
+		{"Default", "PST", TimeZoneAbbreviation{Offset: -8 * 60 * 60}},
+		{"Default", "pdt", TimeZoneAbbreviation{Offset: -7 * 60 * 60, IsDST: true}},
+		{"Default", "EST", TimeZoneAbbreviation{Offset: -5 * 60 * 60}},
+		{"Default", "IST", TimeZoneAbbreviation{Offset: 2 * 60 * 60}},
+		{"Australia", "EST", TimeZoneAbbreviation{Offset: 10 * 60 * 60}},
+		{"Australia", "ACSST", TimeZoneAbbreviation{Offset: 9*60*60 + 30*60}},
+		{"Australia", "PST", TimeZoneAbbreviation{Offset: -8 * 60 * 60}},
+		{"India", "IST", TimeZoneAbbreviation{Offset: 5*60*60 + 30*60}},
+		{"India", "EST", TimeZoneAbbreviation{Offset: -5 * 60 * 60}},
+		{"Default", "MSK", TimeZoneAbbreviation{Zone: "Europe/Moscow"}},
+	} {
+		t.Run(tc.set+"/"+tc.abbr, func(t *testing.T) {
+			a, err := BuiltinTimeZoneAbbreviations(tc.set)
+			require.NoError(t, err)
+			abbr, ok := a.Lookup(tc.abbr)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, abbr)
+		})
+	}
+
+	t.Run("unknown abbreviation", func(t *testing.T) {
+		_, ok := DefaultTimeZoneAbbreviations().Lookup("XYZ")
+		require.False(t, ok)
+	})
+
+	t.Run("unknown set", func(t *testing.T) {
+		_, err := BuiltinTimeZoneAbbreviations("Mars")
+		require.Error(t, err)
+	})
+}
+
+func TestLoadTimeZoneAbbreviations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Base":     {Data: []byte("# Comment\n\nAAA 3600\nBBB 7200 D # Comment\n")},
+		"Override": {Data: []byte("@INCLUDE Base\n@OVERRIDE\nAAA -3600\n")},
+		"Same":     {Data: []byte("@INCLUDE Base\nAAA 3600\n")},
+		"Dynamic":  {Data: []byte("MSK Europe/Moscow\nXXX Mars/Olympus_Mons # Comment\n")},
+	}
+
+	t.Run("include", func(t *testing.T) {
+		a, err := LoadTimeZoneAbbreviations(fsys, "Override")
+		require.NoError(t, err)
+		abbr, ok := a.Lookup("AAA")
+		require.True(t, ok)
+		require.Equal(t, TimeZoneAbbreviation{Offset: -3600}, abbr)
+		abbr, ok = a.Lookup("bbb")
+		require.True(t, ok)
+		require.Equal(t, TimeZoneAbbreviation{Offset: 7200, IsDST: true}, abbr)
+	})
+
+	t.Run("dynamic", func(t *testing.T) {
+		a, err := LoadTimeZoneAbbreviations(fsys, "Dynamic")
+		require.NoError(t, err)
+		abbr, ok := a.Lookup("msk")
+		require.True(t, ok)
+		require.Equal(t, TimeZoneAbbreviation{Zone: "Europe/Moscow"}, abbr)
+
+		// Time zones are only loaded when the abbreviation is used.
+		now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+		_, err = ParseTimestampTZ(DefaultDateStyle(), now, "2020-01-01 12:00 XXX", WithTimeZoneAbbreviations(a))
+		require.Equal(
+			t,
+			NewParseErrorWithKind(ParseErrorKindUnknownTimeZone, 17, "time zone Mars/Olympus_Mons not recognized for time zone abbreviation XXX"),
+			err,
+		)
+	})
+
+	t.Run("identical redefinition", func(t *testing.T) {
+		_, err := LoadTimeZoneAbbreviations(fsys, "Same")
+		require.NoError(t, err)
+	})
+
+	for _, tc := range []struct {
+		desc     string
+		contents string
+		err      string
+	}{
+		{
+			"conflict",
+			"@INCLUDE Base\nAAA -3600\n",
+			`time zone abbreviation "AAA" is multiply defined: entry in time zone file "Test", line 2, conflicts with entry in file "Base", line 3`,
+		},
+		{"missing offset", "AAA\n", `missing time zone offset in time zone file "Test", line 1`},
+		{"invalid offset", "AAA 1h\n", `invalid number for time zone offset in time zone file "Test", line 1`},
+		{"offset out of range", "AAA 54000\n", `time zone offset 54000 is out of range in time zone file "Test", line 1`},
+		{"invalid syntax", "AAA 3600 X\n", `invalid syntax in time zone file "Test", line 1`},
+		{"dynamic with D", "AAA Europe/Moscow D\n", `invalid syntax in time zone file "Test", line 1`},
+		{
+			"too long",
+			"ABCDEFGHIJK 3600\n",
+			`time zone abbreviation "ABCDEFGHIJK" is too long (maximum 10 characters) in time zone file "Test", line 1`,
+		},
+		{"include without file", "@INCLUDE\n", `@INCLUDE without file name in time zone file "Test", line 1`},
+		{"invalid include", "@INCLUDE ../Base\n", `invalid time zone file name "../Base"`},
+		{"recursion", "@INCLUDE Test\n", `time zone file recursion limit exceeded in file "Test"`},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			fsys["Test"] = &fstest.MapFile{Data: []byte(tc.contents)}
+			_, err := LoadTimeZoneAbbreviations(fsys, "Test")
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package pgdatetime
+
+import "time"
+
+// ZoneResolver resolves time zone names and abbreviations when parsing, and
+// the abbreviations of time zones when formatting. Implementations may load
+// time zones from a cache or an embedded copy of the time zone database, or
+// provide synthetic time zones for testing.
+type ZoneResolver interface {
+	// LoadLocation returns the time zone with the given name, e.g.
+	// America/New_York. Names are case insensitive.
+	LoadLocation(name string) (*time.Location, bool)
+	// LookupAbbreviation returns the definition of the given time zone
+	// abbreviation, e.g. PST. Abbreviations are case insensitive.
+	LookupAbbreviation(abbr string) (TimeZoneAbbreviation, bool)
+	// ZoneAbbreviation returns the abbreviation to format the time zone of t
+	// as in the text styles. If false is returned, the numeric offset is
+	// formatted instead.
+	ZoneAbbreviation(t time.Time) (string, bool)
+}
+
+// NewZoneResolver returns a ZoneResolver which loads time zones using
+// time.LoadLocation, and recognizes the given time zone abbreviations.
+func NewZoneResolver(abbrevs *TimeZoneAbbreviations) ZoneResolver {
+	return zoneResolver{abbrevs: abbrevs}
+}
+
+var defaultZoneResolver = NewZoneResolver(DefaultTimeZoneAbbreviations())
+
+// DefaultZoneResolver returns the ZoneResolver used unless another is given,
+// which loads time zones using time.LoadLocation and recognizes the
+// DefaultTimeZoneAbbreviations.
+func DefaultZoneResolver() ZoneResolver {
+	return defaultZoneResolver
+}
+
+// zoneResolver is the ZoneResolver returned by NewZoneResolver.
+type zoneResolver struct {
+	abbrevs *TimeZoneAbbreviations
+}
+
+// LoadLocation implements the ZoneResolver interface.
+func (r zoneResolver) LoadLocation(name string) (*time.Location, bool) {
+	return loadLocation(name)
+}
+
+// LookupAbbreviation implements the ZoneResolver interface.
+func (r zoneResolver) LookupAbbreviation(abbr string) (TimeZoneAbbreviation, bool) {
+	return r.abbrevs.Lookup(abbr)
+}
+
+// ZoneAbbreviation implements the ZoneResolver interface.
+func (r zoneResolver) ZoneAbbreviation(t time.Time) (string, bool) {
+	return t.Format("MST"), true
+}
+
+// abbreviationsZoneResolver wraps a ZoneResolver to recognize the given time
+// zone abbreviations instead of its own.
+type abbreviationsZoneResolver struct {
+	ZoneResolver
+	abbrevs *TimeZoneAbbreviations
+}
+
+// LookupAbbreviation implements the ZoneResolver interface.
+func (r abbreviationsZoneResolver) LookupAbbreviation(abbr string) (TimeZoneAbbreviation, bool) {
+	return r.abbrevs.Lookup(abbr)
+}
@@ -0,0 +1,114 @@
+package pgdatetime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeZoneResolver is a ZoneResolver with a single synthetic time zone.
+type fakeZoneResolver struct{}
+
+var fakeZone = time.FixedZone("Test/Zone", 3*60*60+15*60)
+
+func (fakeZoneResolver) LoadLocation(name string) (*time.Location, bool) {
+	if strings.EqualFold(name, "Test/Zone") {
+		return fakeZone, true
+	}
+	return nil, false
+}
+
+func (fakeZoneResolver) LookupAbbreviation(abbr string) (TimeZoneAbbreviation, bool) {
+	switch strings.ToLower(abbr) {
+	case "tst":
+		return TimeZoneAbbreviation{Offset: 3*60*60 + 15*60}, true
+	case "tdt":
+		return TimeZoneAbbreviation{Zone: "Test/Zone"}, true
+	}
+	return TimeZoneAbbreviation{}, false
+}
+
+func (fakeZoneResolver) ZoneAbbreviation(t time.Time) (string, bool) {
+	if t.Location() == fakeZone {
+		return "TST", true
+	}
+	return "", false
+}
+
+func TestZoneResolver(t *testing.T) {
+	now := time.Date(2020, 06, 26, 15, 16, 17, 123456000, time.UTC)
+	expected := time.Date(2020, 1, 1, 8, 45, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		input    string
+		expected ParseResult
+		err      error
+	}{
+		{"2020-01-01 12:00 Test/Zone", ParseResult{Time: expected.In(fakeZone)}, nil},
+		{"2020-01-01 12:00 test/zone", ParseResult{Time: expected.In(fakeZone)}, nil},
+		{"2020-01-01 12:00 TST", ParseResult{Time: expected.In(time.FixedZone("TST", 3*60*60+15*60))}, nil},
+		{"2020-01-01 12:00 TDT", ParseResult{Time: expected.In(time.FixedZone("TDT", 3*60*60+15*60))}, nil},
+		{
+			"2020-01-01 12:00 America/New_York",
+			ParseResult{},
+			NewParseErrorWithKind(ParseErrorKindUnknownTimeZone, 17, "time zone not recognized: America/New_York"),
+		},
+		{"2020-01-01 12:00 PST", ParseResult{}, NewParseError(17, "unknown string: PST")},
+	} {
+		for _, cfg := range []struct {
+			name string
+			ds   DateStyle
+			opts []ParseOption
+		}{
+			{"option", DefaultDateStyle(), []ParseOption{WithZoneResolver(fakeZoneResolver{})}},
+			{"datestyle", DateStyle{Style: StyleISO, Order: OrderMDY, ZoneResolver: fakeZoneResolver{}}, nil},
+		} {
+			t.Run(cfg.name+"/"+tc.input, func(t *testing.T) {
+				r, err := ParseTimestampTZ(cfg.ds, now, tc.input, cfg.opts...)
+				if tc.err != nil {
+					require.Equal(t, tc.err, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expected.Type, r.Type)
+				require.True(t, tc.expected.Time.Equal(r.Time), "expected %s, got %s", tc.expected.Time, r.Time)
+				expectedName, expectedOffset := tc.expected.Time.Zone()
+				name, offset := r.Time.Zone()
+				require.Equal(t, expectedName, name)
+				require.Equal(t, expectedOffset, offset)
+			})
+		}
+	}
+
+	t.Run("abbreviations", func(t *testing.T) {
+		// Abbreviations given separately replace those of the ZoneResolver,
+		// but time zone names are still loaded using it.
+		opts := []ParseOption{
+			WithZoneResolver(fakeZoneResolver{}),
+			WithTimeZoneAbbreviations(DefaultTimeZoneAbbreviations()),
+		}
+		r, err := ParseTimestampTZ(DefaultDateStyle(), now, "2020-01-01 12:00 Test/Zone", opts...)
+		require.NoError(t, err)
+		require.True(t, expected.Equal(r.Time), "expected %s, got %s", expected, r.Time)
+		r, err = ParseTimestampTZ(DefaultDateStyle(), now, "2020-01-01 12:00 PST", opts...)
+		require.NoError(t, err)
+		require.True(t, time.Date(2020, 1, 1, 20, 0, 0, 0, time.UTC).Equal(r.Time), "got %s", r.Time)
+		_, err = ParseTimestampTZ(DefaultDateStyle(), now, "2020-01-01 12:00 TST", opts...)
+		require.Equal(t, NewParseError(17, "unknown string: TST"), err)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		for _, tc := range []struct {
+			loc      *time.Location
+			expected string
+		}{
+			{fakeZone, "Wed Jan 01 12:00:00 2020 TST"},
+			{time.FixedZone("Other", -8*60*60), "Wed Jan 01 00:45:00 2020 -08"},
+		} {
+			ds := DateStyle{Style: StylePostgres, Order: OrderMDY, ZoneResolver: fakeZoneResolver{}}
+			require.Equal(t, tc.expected, Format(ds, expected.In(tc.loc), true))
+		}
+	})
+}